@@ -0,0 +1,146 @@
+package godenv
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The typed accessors return the default value if the variable is not set or is empty.
+// If the value cannot be converted, they return *Error that points to the assignment of the variable.
+// The errors never include the values, so they are safe to log even if the values hold secrets.
+
+// MustString returns the value of the variable. It panics if the variable is not set.
+func (v *Vars) MustString(key string) string {
+	value, ok := v.Lookup(key)
+	if !ok {
+		panic("godenv: variable " + key + " is not set")
+	}
+
+	return value
+}
+
+// Int returns the value of the variable as int, written in decimal.
+func (v *Vars) Int(key string, def int) (int, error) {
+	value, ok := v.value(key)
+	if !ok {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+
+		return def, v.conversionError(key, "int", err)
+	}
+
+	return n, nil
+}
+
+// Bool returns the value of the variable as bool, or false if the variable is not set or is empty.
+// The values 1, true, yes, on and 0, false, no, off are accepted in any case.
+func (v *Vars) Bool(key string) (bool, error) {
+	return v.BoolOr(key, false)
+}
+
+// BoolOr returns the value of the variable as bool, see Bool.
+func (v *Vars) BoolOr(key string, def bool) (bool, error) {
+	value, ok := v.value(key)
+	if !ok {
+		return def, nil
+	}
+
+	b, err := ParseBool(value)
+	if err != nil {
+		return def, v.conversionError(key, "bool", err)
+	}
+
+	return b, nil
+}
+
+// ParseBool returns the boolean value of the string. The values 1, true, yes, on and 0, false, no, off
+// are accepted in any case. The error does not include the value.
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	default:
+		return false, errors.New("must be one of 1, true, yes, on, 0, false, no, off")
+	}
+}
+
+// Duration returns the value of the variable as time.Duration, see time.ParseDuration.
+func (v *Vars) Duration(key string, def time.Duration) (time.Duration, error) {
+	value, ok := v.value(key)
+	if !ok {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return def, v.conversionError(key, "duration", errors.New("must be a number with a unit such as 300ms, 1.5h or 2h45m"))
+	}
+
+	return d, nil
+}
+
+// URL returns the value of the variable as an absolute URL, or nil if the variable is not set or is empty.
+func (v *Vars) URL(key string) (*url.URL, error) {
+	value, ok := v.value(key)
+	if !ok {
+		return nil, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" {
+		return nil, v.conversionError(key, "URL", errors.New("must be an absolute URL"))
+	}
+
+	return u, nil
+}
+
+// Strings returns the value of the variable split by the separator. The surrounding whitespace of the items
+// is trimmed and the empty items are dropped, e.g. "a, b,,c" is split by "," into "a", "b" and "c".
+// Strings returns nil if the variable is not set or is empty.
+func (v *Vars) Strings(key, sep string) []string {
+	value, ok := v.value(key)
+	if !ok {
+		return nil
+	}
+
+	var items []string
+
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// value returns the value of the variable and reports whether it is set and not empty.
+func (v *Vars) value(key string) (string, bool) {
+	value := v.Get(key)
+	return value, value != ""
+}
+
+// conversionError returns the error that points to the assignment of the variable, if its position is known.
+func (v *Vars) conversionError(key, typ string, err error) error {
+	err = fmt.Errorf("variable %s: invalid %s: %w", key, typ, err)
+
+	variable := v.vars[key]
+	if variable.Pos == (Position{}) {
+		return err
+	}
+
+	return &Error{Pos: variable.Pos, Err: err}
+}
@@ -0,0 +1,106 @@
+package godenv_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+const typed = `PORT=8080
+BAD_PORT=80a
+DEBUG=Yes
+VERBOSE=off
+BAD_BOOL=maybe
+TIMEOUT=1m30s
+BAD_TIMEOUT=90
+API_URL=https://api.example.com/v1
+BAD_URL=api.example.com
+HOSTS="a.example.com, b.example.com,,"
+EMPTY=
+`
+
+func TestVars_TypedAccessors(t *testing.T) {
+	t.Parallel()
+
+	vars := readVars(t, typed)
+
+	port, err := vars.Int("PORT", 80)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	port, err = vars.Int("EMPTY", 80)
+	require.NoError(t, err)
+	assert.Equal(t, 80, port, "the default is returned for the empty values")
+
+	debug, err := vars.Bool("DEBUG")
+	require.NoError(t, err)
+	assert.True(t, debug)
+
+	verbose, err := vars.BoolOr("VERBOSE", true)
+	require.NoError(t, err)
+	assert.False(t, verbose)
+
+	missing, err := vars.Bool("MISSING")
+	require.NoError(t, err)
+	assert.False(t, missing)
+
+	missing, err = vars.BoolOr("MISSING", true)
+	require.NoError(t, err)
+	assert.True(t, missing)
+
+	timeout, err := vars.Duration("TIMEOUT", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	apiURL, err := vars.URL("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, &url.URL{Scheme: "https", Host: "api.example.com", Path: "/v1"}, apiURL)
+
+	apiURL, err = vars.URL("MISSING")
+	require.NoError(t, err)
+	assert.Nil(t, apiURL)
+
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, vars.Strings("HOSTS", ","))
+	assert.Nil(t, vars.Strings("EMPTY", ","))
+
+	assert.Equal(t, "8080", vars.MustString("PORT"))
+	assert.Equal(t, "", vars.MustString("EMPTY"))
+	assert.PanicsWithValue(t, "godenv: variable MISSING is not set", func() { vars.MustString("MISSING") })
+}
+
+func TestVars_TypedAccessors_Errors(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(name, []byte(typed), 0o600))
+
+	vars, err := godenv.ReadFile(name)
+	require.NoError(t, err)
+
+	port, err := vars.Int("BAD_PORT", 80)
+	assert.Equal(t, 80, port)
+	assert.EqualError(t, err, name+":2:1: variable BAD_PORT: invalid int: invalid syntax")
+
+	var envErr *godenv.Error
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, godenv.Position{Filename: name, Line: 2, Column: 1}, envErr.Pos)
+
+	_, err = vars.Bool("BAD_BOOL")
+	assert.EqualError(t, err, name+":5:1: variable BAD_BOOL: invalid bool: must be one of 1, true, yes, on, 0, false, no, off")
+
+	_, err = vars.Duration("BAD_TIMEOUT", time.Second)
+	assert.EqualError(t, err, name+":7:1: variable BAD_TIMEOUT: invalid duration: must be a number with a unit such as 300ms, 1.5h or 2h45m")
+
+	_, err = vars.URL("BAD_URL")
+	assert.EqualError(t, err, name+":9:1: variable BAD_URL: invalid URL: must be an absolute URL")
+
+	_, err = godenv.NewVars(map[string]string{"PORT": "eighty"}).Int("PORT", 80)
+	assert.EqualError(t, err, "variable PORT: invalid int: invalid syntax", "the position is unknown")
+}
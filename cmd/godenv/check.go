@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+func checkCommand() *command {
+	return &command{
+		name:    "check",
+		usage:   "[--schema file] [--allow-unknown] [--format=text|json] [file]",
+		summary: "Validate the .env file against the schema declared in the annotated comments of .env.example",
+		run:     (*cli).check,
+	}
+}
+
+// check exits with 0 if the file conforms to the schema, with 1 if there are violations, and with 2 on errors.
+func (c *cli) check(args []string) int {
+	var (
+		schemaFile   string
+		format       string
+		allowUnknown bool
+	)
+
+	fs := c.flagSet(checkCommand())
+	fs.StringVar(&schemaFile, "schema", ".env.example", "the schema `file`")
+	fs.StringVar(&schemaFile, "s", ".env.example", "shorthand for --schema")
+	fs.StringVar(&format, "format", "text", "output `format`: text or json")
+	fs.BoolVar(&allowUnknown, "allow-unknown", false, "allow the variables that are not declared in the schema")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 1 {
+		c.errorf("check: too many files")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	switch format {
+	case "text", "json":
+	default:
+		c.errorf("check: unknown format %q", format)
+		return exitUsage
+	}
+
+	file := ".env"
+	if fs.NArg() == 1 {
+		file = fs.Arg(0)
+	}
+
+	s, err := schema.ParseFile(schemaFile)
+	if err != nil {
+		c.errorf("check: %v", err)
+		return exitUsage
+	}
+
+	vars, err := godenv.ReadFile(file)
+	if err != nil {
+		c.errorf("check: %v", err)
+		return exitUsage
+	}
+
+	var opts []schema.ValidateOption
+	if allowUnknown {
+		opts = append(opts, schema.AllowUnknown())
+	}
+
+	violations := s.Validate(vars, opts...)
+	for i := range violations {
+		if violations[i].Pos.Filename == "" {
+			// the variable is not set, point to the file it is missing from
+			violations[i].Pos.Filename = file
+		}
+	}
+
+	switch format {
+	case "text":
+		for _, v := range violations {
+			c.printf("%s\n", v)
+		}
+	case "json":
+		if violations == nil {
+			violations = []schema.Violation{}
+		}
+
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(violations); err != nil {
+			c.errorf("check: %v", err)
+			return exitUsage
+		}
+	}
+
+	if len(violations) > 0 {
+		return exitError
+	}
+
+	return exitOK
+}
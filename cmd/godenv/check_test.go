@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	example := filepath.Join(dir, ".env.example")
+	valid := filepath.Join(dir, "valid.env")
+	invalid := filepath.Join(dir, "invalid.env")
+
+	require.NoError(t, os.WriteFile(example, []byte("# @type url\n# @required\nAPI_URL=https://api\n\n# @type port\nPORT=8080\n"), 0o600))
+	require.NoError(t, os.WriteFile(valid, []byte("API_URL=https://example.com\nPORT=443\n"), 0o600))
+	require.NoError(t, os.WriteFile(invalid, []byte("PORT=http\nDEBUG=1\n"), 0o600))
+
+	t.Run("valid", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, "", "check", "--schema", example, valid)
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "check", "-s", example, invalid)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, invalid+": required variable API_URL is not set (declared at "+example+":3:1)\n"+
+			invalid+":1:1: variable PORT of type port: must be a port number from 1 to 65535 (declared at "+example+":6:1)\n"+
+			invalid+":2:1: variable DEBUG is not declared in the schema\n", stdout)
+	})
+
+	t.Run("allow unknown", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "check", "-s", example, "--allow-unknown", invalid)
+		assert.Equal(t, exitError, code)
+		assert.NotContains(t, stdout, "DEBUG")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "check", "-s", example, "--format=json", "--allow-unknown", invalid)
+		assert.Equal(t, exitError, code)
+		assert.JSONEq(t, `[
+			{
+				"key": "API_URL",
+				"message": "required variable API_URL is not set",
+				"position": {"filename": "`+invalid+`", "line": 0, "column": 0},
+				"schema_position": {"filename": "`+example+`", "line": 3, "column": 1}
+			},
+			{
+				"key": "PORT",
+				"message": "variable PORT of type port: must be a port number from 1 to 65535",
+				"position": {"filename": "`+invalid+`", "line": 1, "column": 1},
+				"schema_position": {"filename": "`+example+`", "line": 6, "column": 1}
+			}
+		]`, stdout)
+	})
+
+	t.Run("missing schema", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "check", "-s", filepath.Join(dir, "missing"), valid)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: check: ")
+	})
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/youla-dev/godenv/crypt"
+	"github.com/youla-dev/godenv/internal/edit"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/patch"
+	"github.com/youla-dev/godenv/schema"
+)
+
+func keygenCommand() *command {
+	return &command{
+		name:    "keygen",
+		usage:   "[-o file]",
+		summary: "Generate a key for the encryption of the values",
+		run:     (*cli).keygen,
+	}
+}
+
+func encryptCommand() *command {
+	return &command{
+		name:  "encrypt",
+		usage: "[-f file] [--key-file file] [name]...",
+		summary: "Encrypt the values of the variables in place, or the values of the secrets if no names are given; " +
+			"the key is read from $" + crypt.DefaultKeyEnv + " or " + crypt.DefaultKeyFile + " by default",
+		run: (*cli).encrypt,
+	}
+}
+
+func decryptCommand() *command {
+	return &command{
+		name:    "decrypt",
+		usage:   "[-f file] [--key-file file] [name]...",
+		summary: "Decrypt the values of the variables in place, or all the encrypted values if no names are given",
+		run:     (*cli).decrypt,
+	}
+}
+
+func (c *cli) keygen(args []string) int {
+	var output string
+
+	fs := c.flagSet(keygenCommand())
+	fs.StringVar(&output, "o", "", "write the key to the `file`, which must not exist, instead of the standard output")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	key, err := crypt.GenerateKey()
+	if err != nil {
+		c.errorf("keygen: %v", err)
+		return exitError
+	}
+
+	if output == "" {
+		c.printf("%s\n", key.Encode())
+		return exitOK
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		c.errorf("keygen: %v", err)
+		return exitError
+	}
+
+	if _, err := fmt.Fprintln(f, key.Encode()); err != nil {
+		f.Close()
+		c.errorf("keygen: %v", err)
+
+		return exitError
+	}
+
+	if err := f.Close(); err != nil {
+		c.errorf("keygen: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func (c *cli) encrypt(args []string) int {
+	return c.crypt(encryptCommand(), args)
+}
+
+func (c *cli) decrypt(args []string) int {
+	return c.crypt(decryptCommand(), args)
+}
+
+// crypt encrypts or decrypts the values of the file, the other lines of the file are kept intact.
+func (c *cli) crypt(cmd *command, args []string) int {
+	var file, keyFile string
+
+	fs := c.flagSet(cmd)
+	fs.StringVar(&file, "f", ".env", "the .env `file` to edit")
+	fs.StringVar(&file, "file", ".env", "the .env `file` to edit")
+	fs.StringVar(&keyFile, "key-file", "", "read the key from the `file`")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	src, err := os.ReadFile(file)
+	if err != nil {
+		c.errorf("%s: %v", cmd.name, err)
+		return exitError
+	}
+
+	f, err := edit.Parse(src)
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			c.errorf("%s: %s:%s: %s", cmd.name, file, syntaxErr.Pos, syntaxErr.SafeMsg)
+		} else {
+			c.errorf("%s: %s: %v", cmd.name, file, err)
+		}
+
+		return exitError
+	}
+
+	targets, err := cryptTargets(f, fs.Args(), cmd.name == "encrypt")
+	if err != nil {
+		c.errorf("%s: %s: %v", cmd.name, file, err)
+		return exitError
+	}
+
+	if len(targets) == 0 {
+		return exitOK
+	}
+
+	provider := crypt.DefaultKeyProvider()
+	if keyFile != "" {
+		provider = crypt.KeyFile(keyFile)
+	}
+
+	key, err := provider.Key()
+	if err != nil {
+		c.errorf("%s: %v", cmd.name, err)
+		return exitError
+	}
+
+	for _, a := range targets {
+		var value string
+
+		switch {
+		case cmd.name == "encrypt" && a.Commands:
+			err = errors.New("cannot encrypt a value with command substitution")
+		case cmd.name == "encrypt":
+			value, err = key.Encrypt(a.Name, a.Value)
+		default:
+			value, err = key.Decrypt(a.Name, a.Value)
+		}
+
+		if err == nil && !printer.Writable(value) {
+			err = errors.New("the value cannot be written")
+		}
+
+		if err != nil {
+			c.errorf("%s: %s:%d: variable %s: %v", cmd.name, file, a.Line, a.Name, err)
+			return exitError
+		}
+
+		f.Set(a, value)
+	}
+
+	if err := patch.WriteFile(file, f.Bytes()); err != nil {
+		c.errorf("%s: %v", cmd.name, err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// cryptTargets returns the assignments to encrypt or decrypt: the assignments of the named variables,
+// or, if no names are given, of the secrets or of the encrypted values. The values that are already
+// encrypted or decrypted are skipped.
+func cryptTargets(f *edit.File, names []string, encrypt bool) ([]*edit.Assignment, error) {
+	var candidates []*edit.Assignment
+
+	for _, name := range names {
+		assignments := f.Lookup(name)
+		if len(assignments) == 0 {
+			return nil, fmt.Errorf("variable %s is not assigned", name)
+		}
+
+		candidates = append(candidates, assignments...)
+	}
+
+	if len(names) == 0 {
+		for _, a := range f.Assignments() {
+			if !encrypt || schema.IsSecret(a.Name) {
+				candidates = append(candidates, a)
+			}
+		}
+	}
+
+	var targets []*edit.Assignment
+
+	for _, a := range candidates {
+		if crypt.IsEncrypted(a.Value) == encrypt {
+			continue
+		}
+
+		targets = append(targets, a)
+	}
+
+	return targets, nil
+}
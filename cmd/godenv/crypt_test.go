@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/crypt"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	const plain = "# Database\nDB_HOST=localhost\nDB_PASSWORD='hunter2'\n\n# API\nAPI_TOKEN=\"t0ken\"\nDEBUG=true\n"
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, ".env.key")
+	file := filepath.Join(dir, ".env")
+
+	code, _, stderr := runCLI(t, "", "keygen", "-o", keyFile)
+	require.Equal(t, exitOK, code, stderr)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	code, _, stderr = runCLI(t, "", "keygen", "-o", keyFile)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "file exists", "the key is never overwritten")
+
+	key, err := crypt.KeyFile(keyFile).Key()
+	require.NoError(t, err)
+
+	read := func() string {
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	t.Run("secrets", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(plain), 0o600))
+
+		code, _, stderr := runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile)
+		require.Equal(t, exitOK, code, stderr)
+
+		encrypted := read()
+		assert.NotContains(t, encrypted, "hunter2")
+		assert.NotContains(t, encrypted, "t0ken")
+		assert.Contains(t, encrypted, "# Database\nDB_HOST=localhost\nDB_PASSWORD=enc:v1:")
+		assert.Contains(t, encrypted, "\nDEBUG=true\n", "only the secrets are encrypted")
+
+		vars, err := godenv.ReadFile(file, godenv.WithDecrypter(crypt.Decrypter(crypt.StaticKey(key))))
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", vars.Get("DB_PASSWORD"))
+		assert.Equal(t, "t0ken", vars.Get("API_TOKEN"))
+
+		code, _, stderr = runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, encrypted, read(), "the encrypted values are skipped")
+
+		code, _, stderr = runCLI(t, "", "decrypt", "-f", file, "--key-file", keyFile)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "# Database\nDB_HOST=localhost\nDB_PASSWORD=hunter2\n\n# API\nAPI_TOKEN=t0ken\nDEBUG=true\n", read())
+	})
+
+	t.Run("named variables", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(plain), 0o600))
+
+		code, _, stderr := runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile, "DB_HOST")
+		require.Equal(t, exitOK, code, stderr)
+
+		encrypted := read()
+		assert.True(t, strings.HasPrefix(encrypted, "# Database\nDB_HOST=enc:v1:"))
+		assert.Contains(t, encrypted, "\nDB_PASSWORD='hunter2'\n", "the other values are kept intact")
+
+		code, _, stderr = runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile, "MISSING")
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: encrypt: "+file+": variable MISSING is not assigned\n", stderr)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(plain), 0o600))
+
+		code, _, stderr := runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile)
+		require.Equal(t, exitOK, code, stderr)
+
+		otherKey := filepath.Join(dir, "other.key")
+		code, _, stderr = runCLI(t, "", "keygen", "-o", otherKey)
+		require.Equal(t, exitOK, code, stderr)
+
+		encrypted := read()
+
+		code, _, stderr = runCLI(t, "", "decrypt", "-f", file, "--key-file", otherKey)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: decrypt: "+file+":3: variable DB_PASSWORD: "+crypt.ErrDecrypt.Error()+"\n", stderr)
+		assert.Equal(t, encrypted, read(), "the file is not changed on errors")
+	})
+
+	t.Run("command substitution", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte("DB_PASSWORD=$(pass show db)\n"), 0o600))
+
+		code, _, stderr := runCLI(t, "", "encrypt", "-f", file, "--key-file", keyFile)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: encrypt: "+file+":1: variable DB_PASSWORD: cannot encrypt a value with command substitution\n", stderr)
+	})
+}
+
+func TestRun_Decrypt(t *testing.T) {
+	key, err := crypt.GenerateKey()
+	require.NoError(t, err)
+
+	encrypted, err := key.Encrypt("DB_PASSWORD", "hunter2")
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(file, []byte("GODENV_TEST_HELPER=1\nDB_PASSWORD="+encrypted+"\n"), 0o600))
+
+	setenv(t, crypt.DefaultKeyEnv, key.Encode())
+
+	code, stdout, stderr := runCLI(t, "", "run", "-f", file, "--", os.Args[0], "DB_PASSWORD")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "DB_PASSWORD=hunter2\n", stdout)
+}
+
+func TestRun_UnknownEncryptionScheme(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(file, []byte("GODENV_TEST_HELPER=1\nMODE=enc:plain\n"), 0o600))
+
+	setenv(t, crypt.DefaultKeyEnv, "")
+
+	code, stdout, stderr := runCLI(t, "", "run", "-f", file, "--", os.Args[0], "MODE")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "MODE=enc:plain\n", stdout)
+}
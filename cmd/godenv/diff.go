@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/printer"
+)
+
+func diffCommand() *command {
+	return &command{
+		name:    "diff",
+		usage:   "[--format=unified|json|keys] [--mask] old.env new.env",
+		summary: "Compare the variables of two .env files, ignoring quoting and comments",
+		run:     (*cli).diff,
+	}
+}
+
+// diffVariable is a side of the change in the JSON output.
+type diffVariable struct {
+	Value    string          `json:"value"`
+	Position godenv.Position `json:"position"`
+}
+
+// diffChange is a change in the JSON output.
+type diffChange struct {
+	Kind godenv.ChangeKind `json:"kind"`
+	Key  string            `json:"key"`
+	Old  *diffVariable     `json:"old,omitempty"`
+	New  *diffVariable     `json:"new,omitempty"`
+}
+
+// diff exits with 0 if the files have the same variables, with 1 if they differ, and with 2 on errors, like diff(1).
+func (c *cli) diff(args []string) int {
+	var (
+		format string
+		masked bool
+	)
+
+	fs := c.flagSet(diffCommand())
+	fs.StringVar(&format, "format", "unified", "output `format`: unified, json or keys")
+	fs.BoolVar(&masked, "mask", false, "mask the values, so they never appear in the output")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 2 {
+		c.errorf("diff: two files are required")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	switch format {
+	case "unified", "json", "keys":
+	default:
+		c.errorf("diff: unknown format %q", format)
+		return exitUsage
+	}
+
+	oldName, newName := fs.Arg(0), fs.Arg(1)
+
+	oldVars, err := godenv.ReadFile(oldName)
+	if err != nil {
+		c.errorf("diff: %v", err)
+		return exitUsage
+	}
+
+	newVars, err := godenv.ReadFile(newName)
+	if err != nil {
+		c.errorf("diff: %v", err)
+		return exitUsage
+	}
+
+	changes := godenv.Diff(oldVars, newVars)
+
+	display := func(value string) string {
+		if masked {
+			return godenv.Redacted
+		}
+
+		return value
+	}
+
+	switch format {
+	case "unified":
+		c.printUnifiedDiff(oldName, newName, changes, display)
+	case "json":
+		if err := c.printJSONDiff(changes, display); err != nil {
+			c.errorf("diff: %v", err)
+			return exitUsage
+		}
+	case "keys":
+		for _, change := range changes {
+			c.printf("%c %s\n", changeMark(change.Kind), change.Key)
+		}
+	}
+
+	if len(changes) > 0 {
+		return exitError
+	}
+
+	return exitOK
+}
+
+// printUnifiedDiff prints the removed values with "-" and the added values with "+", a changed value is
+// printed with both.
+func (c *cli) printUnifiedDiff(oldName, newName string, changes []godenv.Change, display func(string) string) {
+	if len(changes) == 0 {
+		return
+	}
+
+	c.printf("--- %s\n+++ %s\n", oldName, newName)
+
+	for _, change := range changes {
+		if change.Kind != godenv.Added {
+			c.printf("-%s=%s\n", change.Key, quote(display(change.Old.Value)))
+		}
+
+		if change.Kind != godenv.Removed {
+			c.printf("+%s=%s\n", change.Key, quote(display(change.New.Value)))
+		}
+	}
+}
+
+func (c *cli) printJSONDiff(changes []godenv.Change, display func(string) string) error {
+	result := make([]diffChange, 0, len(changes))
+
+	for _, change := range changes {
+		dc := diffChange{Kind: change.Kind, Key: change.Key}
+
+		if change.Kind != godenv.Added {
+			dc.Old = &diffVariable{Value: display(change.Old.Value), Position: change.Old.Pos}
+		}
+
+		if change.Kind != godenv.Removed {
+			dc.New = &diffVariable{Value: display(change.New.Value), Position: change.New.Pos}
+		}
+
+		result = append(result, dc)
+	}
+
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(result)
+}
+
+func changeMark(kind godenv.ChangeKind) rune {
+	switch kind {
+	case godenv.Added:
+		return '+'
+	case godenv.Removed:
+		return '-'
+	default:
+		return '~'
+	}
+}
+
+// quote returns the value in the .env notation, if it fits a single line, or as a Go string literal otherwise.
+func quote(value string) string {
+	if quoted, ok := printer.Quote(value); ok {
+		return quoted
+	}
+
+	return strconv.Quote(value)
+}
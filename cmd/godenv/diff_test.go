@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	staging := filepath.Join(dir, "staging.env")
+	prod := filepath.Join(dir, "prod.env")
+
+	require.NoError(t, os.WriteFile(staging, []byte("DB_HOST=db.staging\nDEBUG=true\nPORT=\"8080\"\n"), 0o600))
+	require.NoError(t, os.WriteFile(prod, []byte("# production\nPORT=8080\nDB_HOST='db prod'\nSENTRY_DSN=https://sentry\n"), 0o600))
+
+	t.Run("unified", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "diff", staging, prod)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "--- "+staging+"\n+++ "+prod+"\n"+`-DB_HOST=db.staging
++DB_HOST='db prod'
+-DEBUG=true
++SENTRY_DSN=https://sentry
+`, stdout)
+	})
+
+	t.Run("masked", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "diff", "--mask", staging, prod)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "--- "+staging+"\n+++ "+prod+"\n"+`-DB_HOST='***'
++DB_HOST='***'
+-DEBUG='***'
++SENTRY_DSN='***'
+`, stdout)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "diff", "--format=keys", staging, prod)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "~ DB_HOST\n- DEBUG\n+ SENTRY_DSN\n", stdout)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "diff", "--format=json", "--mask", staging, prod)
+		assert.Equal(t, exitError, code)
+		assert.JSONEq(t, `[
+			{
+				"kind": "changed",
+				"key": "DB_HOST",
+				"old": {"value": "***", "position": {"filename": "`+staging+`", "line": 1, "column": 1}},
+				"new": {"value": "***", "position": {"filename": "`+prod+`", "line": 3, "column": 1}}
+			},
+			{
+				"kind": "removed",
+				"key": "DEBUG",
+				"old": {"value": "***", "position": {"filename": "`+staging+`", "line": 2, "column": 1}}
+			},
+			{
+				"kind": "added",
+				"key": "SENTRY_DSN",
+				"new": {"value": "***", "position": {"filename": "`+prod+`", "line": 4, "column": 1}}
+			}
+		]`, stdout)
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "diff", staging, staging)
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "diff", staging)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: diff: two files are required\n")
+	})
+}
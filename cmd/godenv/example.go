@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+func exampleCommand() *command {
+	return &command{
+		name:    "example",
+		usage:   "[--hints] [--keep-values] [file] | --missing [--schema file] [file]",
+		summary: "Print the example of the .env file without the values, or list the variables of the example missing from it",
+		run:     (*cli).example,
+	}
+}
+
+func (c *cli) example(args []string) int {
+	var (
+		hints      bool
+		keepValues bool
+		missing    bool
+		schemaFile string
+	)
+
+	fs := c.flagSet(exampleCommand())
+	fs.BoolVar(&hints, "hints", false, "replace the values with the placeholders of their types, e.g. <int>")
+	fs.BoolVar(&keepValues, "keep-values", false, "copy the values, except the values of the secrets")
+	fs.BoolVar(&missing, "missing", false, "list the variables of the example that are not set in the file")
+	fs.StringVar(&schemaFile, "schema", ".env.example", "the example `file` for --missing")
+	fs.StringVar(&schemaFile, "s", ".env.example", "shorthand for --schema")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 1 {
+		c.errorf("example: too many files")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	file := ".env"
+	if fs.NArg() == 1 {
+		file = fs.Arg(0)
+	}
+
+	if missing {
+		return c.exampleMissing(schemaFile, file)
+	}
+
+	src, err := os.ReadFile(file)
+	if err != nil {
+		c.errorf("example: %v", err)
+		return exitError
+	}
+
+	var opts []schema.ExampleOption
+	if hints {
+		opts = append(opts, schema.TypeHints())
+	}
+
+	if keepValues {
+		opts = append(opts, schema.KeepValues())
+	}
+
+	out, err := schema.Example(src, opts...)
+	if err != nil {
+		c.errorf("example: %s:%v", file, err)
+		return exitError
+	}
+
+	if _, err := c.stdout.Write(out); err != nil {
+		c.errorf("example: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// exampleMissing exits with 1 if some variables of the example are not set in the file.
+func (c *cli) exampleMissing(schemaFile, file string) int {
+	s, err := schema.ParseFile(schemaFile)
+	if err != nil {
+		c.errorf("example: %v", err)
+		return exitUsage
+	}
+
+	vars, err := godenv.ReadFile(file)
+	if err != nil {
+		c.errorf("example: %v", err)
+		return exitUsage
+	}
+
+	violations := s.Missing(vars)
+	for _, v := range violations {
+		c.printf("%s: %s (declared at %s)\n", file, v.Message, v.SchemaPos)
+	}
+
+	if len(violations) > 0 {
+		return exitError
+	}
+
+	return exitOK
+}
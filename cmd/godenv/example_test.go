@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExample(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	env := filepath.Join(dir, ".env")
+	example := filepath.Join(dir, ".env.example")
+
+	require.NoError(t, os.WriteFile(env, []byte("# Service\nPORT=8080\nAPI_TOKEN=secret\n"), 0o600))
+	require.NoError(t, os.WriteFile(example, []byte("PORT=\nAPI_TOKEN=\n\n# @type url\nAPI_URL=\n"), 0o600))
+
+	t.Run("example", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, "", "example", env)
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "# Service\nPORT=\nAPI_TOKEN=\n", stdout)
+	})
+
+	t.Run("hints", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "example", "--hints", env)
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "# Service\nPORT='<int>'\nAPI_TOKEN=\n", stdout)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "example", "--missing", "-s", example, env)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, env+": variable API_URL is not set (declared at "+example+":5:1)\n", stdout)
+	})
+
+	t.Run("nothing missing", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "example", "--missing", "-s", env, example)
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+func explainCommand() *command {
+	return &command{
+		name:    "explain",
+		usage:   "[-f file]... [--schema file] [--override] [--only] [--set name=value]... name",
+		summary: "Explain where the value of the variable comes from, as seen by the command run by godenv run",
+		run:     (*cli).explain,
+	}
+}
+
+func (c *cli) explain(args []string) int {
+	var (
+		cfg        runConfig
+		schemaFile string
+	)
+
+	fs := c.flagSet(explainCommand())
+	fs.Var(&cfg.files, "f", "`file` to load, may be repeated: the later files override the earlier ones (default .env)")
+	fs.Var(&cfg.files, "file", "alias for -f")
+	fs.StringVar(&schemaFile, "schema", "", "take the default values from the schema `file`")
+	fs.StringVar(&schemaFile, "s", "", "shorthand for --schema")
+	fs.BoolVar(&cfg.override, "override", false, "the variables of the files override the variables of the environment")
+	fs.BoolVar(&cfg.only, "only", false, "ignore the variables of the environment")
+	fs.Var(&cfg.set, "set", "set the variable, overriding the files and the environment: `name=value`, may be repeated")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		c.errorf("explain: expected one variable name")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	if len(cfg.files) == 0 {
+		cfg.files = stringsFlag{".env"}
+	}
+
+	// The sources from the lowest precedence to the highest.
+	var sources []godenv.Source
+
+	if schemaFile != "" {
+		s, err := schema.ParseFile(schemaFile)
+		if err != nil {
+			c.errorf("explain: %v", err)
+			return exitError
+		}
+
+		sources = append(sources, s.DefaultVars())
+	}
+
+	files, err := loadFiles(cfg.files)
+	if err != nil {
+		c.errorf("explain: %v", err)
+		return exitError
+	}
+
+	switch {
+	case cfg.only:
+		sources = append(sources, files)
+	case cfg.override:
+		sources = append(sources, godenv.EnvSource{}, files)
+	default:
+		sources = append(sources, files, godenv.EnvSource{})
+	}
+
+	sources = append(sources, godenv.MapSource(cfg.set))
+	vars := godenv.NewLayered(sources...).Vars()
+
+	name := fs.Arg(0)
+
+	e, ok := vars.Explain(name)
+	if !ok {
+		c.errorf("explain: variable %s is not set", name)
+		return exitError
+	}
+
+	c.printf("%s=%s\n", name, explainValue(vars, e.Variable))
+	c.printf("  set by %s\n", explainOrigin(e.Variable))
+
+	for _, v := range e.Overridden {
+		c.printf("  overrides %s set by %s\n", explainValue(vars, v), explainOrigin(v))
+	}
+
+	return exitOK
+}
+
+// explainValue returns the value of the variable, quoted unless it is redacted.
+func explainValue(vars *godenv.Vars, v godenv.Variable) string {
+	if vars.IsRedacted(v.Name) {
+		return godenv.Redacted
+	}
+
+	return quote(v.Value)
+}
+
+func explainOrigin(v godenv.Variable) string {
+	switch v.Origin {
+	case godenv.OriginFile:
+		return v.Pos.String()
+	case godenv.OriginEnvironment:
+		return "the environment"
+	case godenv.OriginDefault:
+		if v.Pos == (godenv.Position{}) {
+			return "the default"
+		}
+
+		return "the default declared at " + v.Pos.String()
+	case godenv.OriginOverride:
+		return "--set"
+	default:
+		return v.Origin.String()
+	}
+}
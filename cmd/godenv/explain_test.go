@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	example := filepath.Join(dir, ".env.example")
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+
+	require.NoError(t, os.WriteFile(example, []byte("# @default warn\nGODENV_TEST_LEVEL=\n# @default 1\nGODENV_TEST_ONLY_DEFAULT=\n"), 0o600))
+	require.NoError(t, os.WriteFile(base, []byte("GODENV_TEST_LEVEL=info\nGODENV_TEST_TOKEN=abc\n"), 0o600))
+	require.NoError(t, os.WriteFile(local, []byte("\nGODENV_TEST_LEVEL=debug\nGODENV_TEST_LEVEL='very verbose'\n"), 0o600))
+
+	setenv(t, "GODENV_TEST_LEVEL", "error")
+	setenv(t, "GODENV_TEST_TOKEN", "xyz")
+
+	files := []string{"explain", "-f", base, "-f", local, "--schema", example}
+
+	code, stdout, stderr := runCLI(t, "", append(files, "GODENV_TEST_LEVEL")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "GODENV_TEST_LEVEL=error\n"+
+		"  set by the environment\n"+
+		"  overrides 'very verbose' set by "+local+":3:1\n"+
+		"  overrides debug set by "+local+":2:1\n"+
+		"  overrides info set by "+base+":1:1\n"+
+		"  overrides warn set by the default declared at "+example+":2:1\n", stdout)
+
+	code, stdout, stderr = runCLI(t, "", append(files, "--override", "GODENV_TEST_LEVEL")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "GODENV_TEST_LEVEL='very verbose'\n"+
+		"  set by "+local+":3:1\n"+
+		"  overrides debug set by "+local+":2:1\n"+
+		"  overrides info set by "+base+":1:1\n"+
+		"  overrides error set by the environment\n"+
+		"  overrides warn set by the default declared at "+example+":2:1\n", stdout)
+
+	code, stdout, stderr = runCLI(t, "", append(files, "--set", "GODENV_TEST_LEVEL=trace", "GODENV_TEST_LEVEL")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "GODENV_TEST_LEVEL=trace\n"+
+		"  set by --set\n"+
+		"  overrides error set by the environment\n"+
+		"  overrides 'very verbose' set by "+local+":3:1\n"+
+		"  overrides debug set by "+local+":2:1\n"+
+		"  overrides info set by "+base+":1:1\n"+
+		"  overrides warn set by the default declared at "+example+":2:1\n", stdout)
+
+	code, _, stderr = runCLI(t, "", append(files, "--set", "=value", "GODENV_TEST_LEVEL")...)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected NAME=value")
+
+	code, stdout, stderr = runCLI(t, "", append(files, "--only", "GODENV_TEST_TOKEN")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "GODENV_TEST_TOKEN=***\n  set by "+base+":2:1\n", stdout, "the secrets are redacted")
+
+	code, stdout, stderr = runCLI(t, "", append(files, "GODENV_TEST_ONLY_DEFAULT")...)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "GODENV_TEST_ONLY_DEFAULT=1\n  set by the default declared at "+example+":4:1\n", stdout)
+
+	code, _, stderr = runCLI(t, "", "explain", "-f", base, "GODENV_TEST_MISSING")
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "godenv: explain: variable GODENV_TEST_MISSING is not set\n", stderr)
+
+	code, _, _ = runCLI(t, "", "explain", "-f", base)
+	assert.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/export"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		usage:   "--format=format [--name name] [--namespace namespace] [file]",
+		summary: "Print the variables of the .env file in the format of another tool",
+		run:     (*cli).export,
+	}
+}
+
+func (c *cli) export(args []string) int {
+	var (
+		format    string
+		name      string
+		namespace string
+	)
+
+	names := make([]string, 0, len(export.Formats()))
+	for _, f := range export.Formats() {
+		names = append(names, f.Name)
+	}
+
+	fs := c.flagSet(exportCommand())
+	fs.StringVar(&format, "format", "", "output `format`: "+strings.Join(names, ", "))
+	fs.StringVar(&name, "name", "env", "the `name` of the Kubernetes ConfigMap or Secret")
+	fs.StringVar(&namespace, "namespace", "", "the `namespace` of the Kubernetes ConfigMap or Secret")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 1 {
+		c.errorf("export: too many files")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	if _, ok := export.LookupFormat(format); !ok {
+		if format == "" {
+			c.errorf("export: --format is required")
+		} else {
+			c.errorf("export: unknown format %q", format)
+		}
+
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	file := ".env"
+	if fs.NArg() == 1 {
+		file = fs.Arg(0)
+	}
+
+	vars, err := godenv.ReadFile(file)
+	if err != nil {
+		c.errorf("export: %v", err)
+		return exitError
+	}
+
+	opts := []export.Option{export.Name(name)}
+	if namespace != "" {
+		opts = append(opts, export.Namespace(namespace))
+	}
+
+	if err := export.Write(c.stdout, format, vars, opts...); err != nil {
+		c.errorf("export: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	env := filepath.Join(dir, ".env")
+
+	require.NoError(t, os.WriteFile(env, []byte("# Service\nPORT=8080\nNAME=\"it's\"\n"), 0o600))
+
+	t.Run("shell", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, "", "export", "--format=shell", env)
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "export PORT='8080'\nexport NAME='it'\\''s'\n", stdout)
+	})
+
+	t.Run("configmap", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, "", "export", "--format=configmap", "--name=app", "--namespace=prod", env)
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"app\"\n  namespace: \"prod\"\n"+
+			"data:\n  PORT: \"8080\"\n  NAME: \"it's\"\n", stdout)
+	})
+
+	t.Run("missing format", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "export", env)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: export: --format is required\n")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "export", "--format=toml", env)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: export: unknown format \"toml\"\n")
+	})
+}
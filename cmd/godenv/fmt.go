@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/youla-dev/godenv/format"
+	"github.com/youla-dev/godenv/internal/diff"
+	"github.com/youla-dev/godenv/patch"
+)
+
+const stdinName = "<standard input>"
+
+func fmtCommand() *command {
+	return &command{
+		name:    "fmt",
+		usage:   "[-w] [-l] [-d] [--sort] [file]...",
+		summary: "Rewrite the .env files in the canonical form",
+		run:     (*cli).fmt,
+	}
+}
+
+// fmtConfig describes what to do with the formatted files.
+type fmtConfig struct {
+	write    bool
+	list     bool
+	diff     bool
+	sortKeys bool
+}
+
+func (c *cli) fmt(args []string) int {
+	var cfg fmtConfig
+
+	fs := c.flagSet(fmtCommand())
+	fs.BoolVar(&cfg.write, "w", false, "write the result to the file instead of the standard output")
+	fs.BoolVar(&cfg.list, "l", false, "list the files whose formatting differs from the canonical form")
+	fs.BoolVar(&cfg.diff, "d", false, "print the differences instead of the formatted file")
+	fs.BoolVar(&cfg.sortKeys, "sort", false, "sort the keys within the groups delimited by blank lines and comments")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() == 0 {
+		if cfg.write {
+			c.errorf("fmt: cannot use -w with the standard input")
+			return exitUsage
+		}
+
+		src, err := io.ReadAll(c.stdin)
+		if err != nil {
+			c.errorf("fmt: %v", err)
+			return exitError
+		}
+
+		return c.formatFile(stdinName, src, cfg)
+	}
+
+	code := exitOK
+
+	for _, name := range fs.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			c.errorf("fmt: %v", err)
+			code = exitError
+
+			continue
+		}
+
+		if c.formatFile(name, src, cfg) != exitOK {
+			code = exitError
+		}
+	}
+
+	return code
+}
+
+// formatFile formats the content of the file and reports the result as configured.
+func (c *cli) formatFile(name string, src []byte, cfg fmtConfig) int {
+	var opts []format.Option
+	if cfg.sortKeys {
+		opts = append(opts, format.SortKeys())
+	}
+
+	res, err := format.Source(src, opts...)
+	if err != nil {
+		c.errorf("fmt: %s:%v", name, err)
+		return exitError
+	}
+
+	changed := !bytes.Equal(src, res)
+
+	if cfg.list && changed {
+		c.printf("%s\n", name)
+	}
+
+	if cfg.diff && changed {
+		c.printf("%s", diff.Unified(name+".orig", name, string(src), string(res)))
+	}
+
+	if cfg.write && changed {
+		if err := patch.WriteFile(name, res); err != nil {
+			c.errorf("fmt: %v", err)
+			return exitError
+		}
+	}
+
+	if !cfg.list && !cfg.diff && !cfg.write {
+		c.printf("%s", res)
+	}
+
+	return exitOK
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFmt(t *testing.T) {
+	t.Parallel()
+
+	const (
+		unformatted = "\nB=\"1\"\nA=2  \n\n\n# comment \n"
+		formatted   = "B=1\nA=2\n\n# comment\n"
+	)
+
+	t.Run("standard input", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, unformatted, "fmt")
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, formatted, stdout)
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, unformatted, "fmt", "--sort")
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "A=2\nB=1\n\n# comment\n", stdout)
+	})
+
+	t.Run("list, diff and write", func(t *testing.T) {
+		dir := t.TempDir()
+		dirty := filepath.Join(dir, "dirty.env")
+		clean := filepath.Join(dir, "clean.env")
+
+		require.NoError(t, os.WriteFile(dirty, []byte(unformatted), 0o600))
+		require.NoError(t, os.WriteFile(clean, []byte(formatted), 0o600))
+
+		code, stdout, _ := runCLI(t, "", "fmt", "-l", dirty, clean)
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, dirty+"\n", stdout)
+
+		code, stdout, _ = runCLI(t, "", "fmt", "-d", dirty, clean)
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "--- "+dirty+".orig\n+++ "+dirty+"\n"+
+			"@@ -1,6 +1,4 @@\n-\n-B=\"1\"\n-A=2  \n-\n+B=1\n+A=2\n \n-# comment \n+# comment\n", stdout)
+
+		code, stdout, _ = runCLI(t, "", "fmt", "-w", dirty, clean)
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+
+		content, err := os.ReadFile(dirty)
+		require.NoError(t, err)
+		assert.Equal(t, formatted, string(content))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "FOO= bar\n", "fmt")
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: fmt: <standard input>:1:5: unexpected token: SPACE( )\n", stderr)
+	})
+
+	t.Run("write standard input", func(t *testing.T) {
+		code, _, _ := runCLI(t, unformatted, "fmt", "-w")
+		assert.Equal(t, exitUsage, code)
+	})
+}
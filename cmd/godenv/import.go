@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/youla-dev/godenv/importer"
+)
+
+func importCommand() *command {
+	return &command{
+		name:    "import",
+		usage:   "--format=format [--separator sep] [--sanitize] [file]",
+		summary: "Convert the variables from the format of another tool into the .env format",
+		run:     (*cli).importVars,
+	}
+}
+
+// importVars reads the standard input if no file is given.
+func (c *cli) importVars(args []string) int {
+	var (
+		format    string
+		separator string
+		sanitize  bool
+	)
+
+	names := make([]string, 0, len(importer.Formats()))
+	for _, f := range importer.Formats() {
+		names = append(names, f.Name)
+	}
+
+	fs := c.flagSet(importCommand())
+	fs.StringVar(&format, "format", "", "input `format`: "+strings.Join(names, ", "))
+	fs.StringVar(&separator, "separator", "__", "the `separator` of the nested keys of JSON and YAML")
+	fs.BoolVar(&sanitize, "sanitize", false, "replace the characters that are not allowed in the names with _")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 1 {
+		c.errorf("import: too many files")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	if _, ok := importer.LookupFormat(format); !ok {
+		if format == "" {
+			c.errorf("import: --format is required")
+		} else {
+			c.errorf("import: unknown format %q", format)
+		}
+
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	var input io.Reader = c.stdin
+
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			c.errorf("import: %v", err)
+			return exitError
+		}
+		defer f.Close()
+
+		input = f
+	}
+
+	opts := []importer.Option{importer.Separator(separator)}
+	if sanitize {
+		opts = append(opts, importer.Sanitize())
+	}
+
+	if err := importer.Convert(c.stdout, input, format, opts...); err != nil {
+		c.errorf("import: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	properties := filepath.Join(dir, "app.properties")
+
+	require.NoError(t, os.WriteFile(properties, []byte("db.host=localhost\ndb.port=5432\n"), 0o600))
+
+	t.Run("file", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, "", "import", "--format=properties", properties)
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "db.host=localhost\ndb.port=5432\n", stdout)
+	})
+
+	t.Run("standard input", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, `{"DB": {"HOST": "localhost"}}`, "import", "--format=json", "--separator=_")
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "DB_HOST=localhost\n", stdout)
+	})
+
+	t.Run("sanitize", func(t *testing.T) {
+		code, _, stderr := runCLI(t, `{"my var": "1"}`, "import", "--format=json")
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: import: json: invalid variable name: \"my var\"\n", stderr)
+
+		code, stdout, stderr := runCLI(t, `{"my var": "1"}`, "import", "--format=json", "--sanitize")
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "my_var=1\n", stdout)
+	})
+
+	t.Run("missing format", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "import", properties)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: import: --format is required\n")
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/youla-dev/godenv/lint"
+)
+
+func lintCommand() *command {
+	return &command{
+		name:    "lint",
+		usage:   "[--format=text|json|sarif] [--enable rules] [--disable rules] [file]...",
+		summary: "Check the .env files for problems",
+		run:     (*cli).lint,
+	}
+}
+
+func (c *cli) lint(args []string) int {
+	var (
+		format  string
+		enable  string
+		disable string
+	)
+
+	names := make([]string, 0, len(lint.Rules()))
+	for _, rule := range lint.Rules() {
+		names = append(names, rule.Name)
+	}
+
+	fs := c.flagSet(lintCommand())
+	fs.StringVar(&format, "format", "text", "output `format`: text, json or sarif")
+	fs.StringVar(&enable, "enable", "", "comma-separated `rules` to check, all by default: "+strings.Join(names, ", "))
+	fs.StringVar(&disable, "disable", "", "comma-separated `rules` not to check")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		c.errorf("lint: unknown format %q", format)
+		return exitUsage
+	}
+
+	var opts []lint.Option
+	if enable != "" {
+		opts = append(opts, lint.Enable(splitList(enable)...))
+	}
+
+	if disable != "" {
+		opts = append(opts, lint.Disable(splitList(disable)...))
+	}
+
+	linter, err := lint.New(opts...)
+	if err != nil {
+		c.errorf("lint: %v", err)
+		return exitUsage
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{".env"}
+	}
+
+	var findings []lint.Finding
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			c.errorf("lint: %v", err)
+			return exitError
+		}
+
+		findings = append(findings, linter.Lint(file, src)...)
+	}
+
+	switch format {
+	case "text":
+		err = lint.WriteText(c.stdout, findings)
+	case "json":
+		err = lint.WriteJSON(c.stdout, findings)
+	case "sarif":
+		err = lint.WriteSARIF(c.stdout, findings, linter.Rules())
+	}
+
+	if err != nil {
+		c.errorf("lint: %v", err)
+		return exitError
+	}
+
+	if len(findings) > 0 {
+		return exitError
+	}
+
+	return exitOK
+}
+
+// splitList splits the comma-separated list, ignoring the blanks around the items.
+func splitList(list string) []string {
+	var items []string
+
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
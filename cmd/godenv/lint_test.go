@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.env")
+	dirty := filepath.Join(dir, "dirty.env")
+
+	require.NoError(t, os.WriteFile(clean, []byte("BAR=1\nFOO=2\n"), 0o600))
+	require.NoError(t, os.WriteFile(dirty, []byte("FOO=1\nfoo=2 # two\n"), 0o600))
+
+	t.Run("no findings", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "lint", clean)
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("findings", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "lint", clean, dirty)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t,
+			dirty+":2:1: key foo contains lowercase letters (lowercase-key)\n"+
+				dirty+`:2:1: the unquoted value of foo contains "#", that is not a comment (unquoted-hash)`+"\n",
+			stdout,
+		)
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "lint", "--enable=lowercase-key,unquoted-hash", "--disable", "unquoted-hash", dirty)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, dirty+":2:1: key foo contains lowercase letters (lowercase-key)\n", stdout)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "lint", "--format=json", clean)
+		assert.Equal(t, exitOK, code)
+		assert.JSONEq(t, "[]", stdout)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "lint", "--format=xml", clean)
+		assert.Equal(t, exitUsage, code)
+		assert.Equal(t, "godenv: lint: unknown format \"xml\"\n", stderr)
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "lint", "--disable=nope", clean)
+		assert.Equal(t, exitUsage, code)
+		assert.Equal(t, "godenv: lint: unknown rule \"nope\"\n", stderr)
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/youla-dev/godenv/internal/lsp"
+)
+
+func lspCommand() *command {
+	return &command{
+		name:    "lsp",
+		usage:   "[--schema file]",
+		summary: "Run the language server of the .env files, talking to the editor over the standard input and output",
+		run:     (*cli).lsp,
+	}
+}
+
+func (c *cli) lsp(args []string) int {
+	var schemaFile string
+
+	fs := c.flagSet(lspCommand())
+	fs.StringVar(&schemaFile, "schema", "", "complete the names of the variables declared in the schema `file` (default .env.example next to the file)")
+	fs.StringVar(&schemaFile, "s", "", "shorthand for --schema")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() > 0 {
+		c.errorf("lsp: unexpected arguments")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	opts := []lsp.Option{lsp.WithLookup(os.LookupEnv)}
+	if schemaFile != "" {
+		opts = append(opts, lsp.WithSchema(schemaFile))
+	}
+
+	if err := lsp.NewServer(opts...).Serve(c.stdin, c.stdout); err != nil {
+		c.errorf("lsp: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
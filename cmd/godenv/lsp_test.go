@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLSP(t *testing.T) {
+	t.Parallel()
+
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	t.Run("session", func(t *testing.T) {
+		session := frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+			frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///.env","text":"a=1\n"}}}`) +
+			frame(`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`) +
+			frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+		code, stdout, stderr := runCLI(t, session, "lsp")
+		assert.Equal(t, exitOK, code, stderr)
+		assert.Contains(t, stdout, `"hoverProvider":true`)
+		assert.Contains(t, stdout, `"code":"lowercase-key"`)
+		assert.Contains(t, stdout, frame(`{"jsonrpc":"2.0","id":2,"result":null}`))
+	})
+
+	t.Run("exit without shutdown", func(t *testing.T) {
+		code, _, stderr := runCLI(t, frame(`{"jsonrpc":"2.0","method":"exit"}`), "lsp")
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: lsp: exit without shutdown\n", stderr)
+	})
+}
@@ -0,0 +1,209 @@
+// Command godenv works with the .env files: it runs programs with the variables loaded from the files.
+//
+// Usage:
+//
+//	godenv <command> [flags] [arguments]
+//
+// Run "godenv help <command>" for the details of the command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// cli holds the standard streams of the process, so the commands can be run in tests.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a godenv subcommand.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+func main() {
+	c := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	os.Exit(c.main(os.Args[1:]))
+}
+
+func commands() []*command {
+	return []*command{
+		runCommand(),
+		lintCommand(),
+		fmtCommand(),
+		diffCommand(),
+		checkCommand(),
+		explainCommand(),
+		getCommand(),
+		setCommand(),
+		unsetCommand(),
+		keysCommand(),
+		exampleCommand(),
+		exportCommand(),
+		importCommand(),
+		keygenCommand(),
+		encryptCommand(),
+		decryptCommand(),
+		lspCommand(),
+	}
+}
+
+func (c *cli) main(args []string) int {
+	if len(args) == 0 {
+		c.usage(c.stderr)
+		return exitUsage
+	}
+
+	name, args := args[0], args[1:]
+
+	switch name {
+	case "help", "-h", "-help", "--help":
+		return c.help(args)
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		c.errorf("unknown command %q", name)
+		c.usage(c.stderr)
+
+		return exitUsage
+	}
+
+	return cmd.run(c, args)
+}
+
+func (c *cli) help(args []string) int {
+	if len(args) == 0 {
+		c.usage(c.stdout)
+		return exitOK
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		c.errorf("unknown command %q", args[0])
+		return exitUsage
+	}
+
+	return cmd.run(c, []string{"-h"})
+}
+
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: godenv <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "godenv help <command>" for the details of the command.`)
+}
+
+// flagSet returns a flag set of the command, that prints the usage of the command on -h.
+func (c *cli) flagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: godenv %s %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses the flags of the command. If it returns false, the command must exit with the code.
+func (c *cli) parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
+		}
+
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
+
+func (c *cli) printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.stdout, format, args...)
+}
+
+func (c *cli) errorf(format string, args ...interface{}) {
+	fmt.Fprintf(c.stderr, "godenv: "+format+"\n", args...)
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// stringsFlag is a flag that can be repeated, each occurrence adds a value.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// assignmentsFlag is a flag of the "NAME=value" assignments that can be repeated.
+type assignmentsFlag map[string]string
+
+func (f *assignmentsFlag) String() string {
+	assignments := make([]string, 0, len(*f))
+	for name, value := range *f {
+		assignments = append(assignments, name+"="+value)
+	}
+
+	sort.Strings(assignments)
+
+	return strings.Join(assignments, ",")
+}
+
+func (f *assignmentsFlag) Set(value string) error {
+	i := strings.IndexByte(value, '=')
+	if i <= 0 {
+		return errors.New("expected NAME=value")
+	}
+
+	name, value := value[:i], value[i+1:]
+
+	if *f == nil {
+		*f = make(assignmentsFlag)
+	}
+
+	(*f)[name] = value
+
+	return nil
+}
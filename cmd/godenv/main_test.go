@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain makes the test binary act as the command run by godenv, if the GODENV_TEST_HELPER variable is set.
+// The helper prints the variables named in the arguments and exits with the code from GODENV_TEST_EXIT,
+// or kills itself if GODENV_TEST_KILL is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("GODENV_TEST_HELPER") != "1" {
+		os.Exit(m.Run())
+	}
+
+	for _, name := range os.Args[1:] {
+		if value, ok := os.LookupEnv(name); ok {
+			fmt.Printf("%s=%s\n", name, value)
+		} else {
+			fmt.Printf("%s is unset\n", name)
+		}
+	}
+
+	if os.Getenv("GODENV_TEST_KILL") == "1" {
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Kill()
+			time.Sleep(time.Minute)
+		}
+	}
+
+	code, _ := strconv.Atoi(os.Getenv("GODENV_TEST_EXIT"))
+	os.Exit(code)
+}
+
+// runCLI runs godenv with the arguments, reading the standard input from stdin,
+// and returns the exit code and the standard output and error.
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	code := c.main(args)
+
+	return code, stdout.String(), stderr.String()
+}
+
+// setenv sets the environment variable for the duration of the test, like t.Setenv in Go 1.17.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
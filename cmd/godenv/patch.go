@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/youla-dev/godenv/patch"
+)
+
+func getCommand() *command {
+	return &command{
+		name:    "get",
+		usage:   "name [file]",
+		summary: "Print the value of the variable as it is written in the .env file",
+		run:     (*cli).get,
+	}
+}
+
+func setCommand() *command {
+	return &command{
+		name:    "set",
+		usage:   "[--after name] name=value [file]",
+		summary: "Assign the value to the variable in the .env file, keeping the other lines intact",
+		run:     (*cli).set,
+	}
+}
+
+func unsetCommand() *command {
+	return &command{
+		name:    "unset",
+		usage:   "name [file]",
+		summary: "Remove the assignments of the variable from the .env file",
+		run:     (*cli).unset,
+	}
+}
+
+func keysCommand() *command {
+	return &command{
+		name:    "keys",
+		usage:   "[file]",
+		summary: "Print the names of the variables assigned in the .env file",
+		run:     (*cli).keys,
+	}
+}
+
+func (c *cli) get(args []string) int {
+	fs := c.flagSet(getCommand())
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	file, ok := c.patchFile(fs, 1)
+	if !ok {
+		return exitUsage
+	}
+
+	value, err := patch.GetFile(file, fs.Arg(0))
+	if err != nil {
+		c.errorf("get: %v", err)
+		return exitError
+	}
+
+	c.printf("%s\n", value)
+
+	return exitOK
+}
+
+func (c *cli) set(args []string) int {
+	var after string
+
+	fs := c.flagSet(setCommand())
+	fs.StringVar(&after, "after", "", "add a new variable after the variable `name` instead of the end of the file")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	file, ok := c.patchFile(fs, 1)
+	if !ok {
+		return exitUsage
+	}
+
+	i := strings.IndexByte(fs.Arg(0), '=')
+	if i <= 0 {
+		c.errorf("set: expected NAME=value, got %q", fs.Arg(0))
+		return exitUsage
+	}
+
+	name, value := fs.Arg(0)[:i], fs.Arg(0)[i+1:]
+
+	var opts []patch.Option
+	if after != "" {
+		opts = append(opts, patch.After(after))
+	}
+
+	if err := patch.SetFile(file, name, value, opts...); err != nil {
+		c.errorf("set: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func (c *cli) unset(args []string) int {
+	fs := c.flagSet(unsetCommand())
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	file, ok := c.patchFile(fs, 1)
+	if !ok {
+		return exitUsage
+	}
+
+	if err := patch.UnsetFile(file, fs.Arg(0)); err != nil {
+		c.errorf("unset: %v", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func (c *cli) keys(args []string) int {
+	fs := c.flagSet(keysCommand())
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	file, ok := c.patchFile(fs, 0)
+	if !ok {
+		return exitUsage
+	}
+
+	keys, err := patch.KeysFile(file)
+	if err != nil {
+		c.errorf("keys: %v", err)
+		return exitError
+	}
+
+	for _, key := range keys {
+		c.printf("%s\n", key)
+	}
+
+	return exitOK
+}
+
+// patchFile returns the file given after the n arguments of the command, or .env if it is not given.
+// It reports false if the number of the arguments is wrong.
+func (c *cli) patchFile(fs *flag.FlagSet, n int) (string, bool) {
+	if fs.NArg() < n || fs.NArg() > n+1 {
+		c.errorf("%s: wrong number of arguments", fs.Name())
+		fs.Usage()
+
+		return "", false
+	}
+
+	if fs.NArg() == n+1 {
+		return fs.Arg(n), true
+	}
+
+	return ".env", true
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSetUnsetKeys(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(file, []byte("# Database\nDB_HOST=localhost\n\n# API\nAPI_URL=http://localhost\n"), 0o644))
+
+	read := func() string {
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	code, stdout, stderr := runCLI(t, "", "get", "DB_HOST", file)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "localhost\n", stdout)
+
+	code, _, stderr = runCLI(t, "", "set", "DB_HOST=db internal", file)
+	require.Equal(t, exitOK, code, stderr)
+
+	code, _, stderr = runCLI(t, "", "set", "--after", "DB_HOST", "DB_PASSWORD=it's secret", file)
+	require.Equal(t, exitOK, code, stderr)
+
+	code, _, stderr = runCLI(t, "", "set", "DEBUG=", file)
+	require.Equal(t, exitOK, code, stderr)
+
+	assert.Equal(t, "# Database\nDB_HOST='db internal'\nDB_PASSWORD=\"it's secret\"\n\n# API\nAPI_URL=http://localhost\nDEBUG=\n", read())
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	code, stdout, stderr = runCLI(t, "", "keys", file)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "DB_HOST\nDB_PASSWORD\nAPI_URL\nDEBUG\n", stdout)
+
+	code, _, stderr = runCLI(t, "", "unset", "DB_PASSWORD", file)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "# Database\nDB_HOST='db internal'\n\n# API\nAPI_URL=http://localhost\nDEBUG=\n", read())
+
+	t.Run("errors", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "get", "DB_PASSWORD", file)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: get: "+file+": variable is not assigned: DB_PASSWORD\n", stderr)
+
+		code, _, stderr = runCLI(t, "", "unset", "DB_PASSWORD", file)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: unset: "+file+": variable is not assigned: DB_PASSWORD\n", stderr)
+
+		code, _, stderr = runCLI(t, "", "set", "--after", "MISSING", "A=1", file)
+		assert.Equal(t, exitError, code)
+		assert.Equal(t, "godenv: set: "+file+": variable is not assigned: MISSING\n", stderr)
+
+		code, _, stderr = runCLI(t, "", "set", "DB_HOST", file)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, `godenv: set: expected NAME=value, got "DB_HOST"`)
+
+		code, _, stderr = runCLI(t, "", "get", file, file, file)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: get: wrong number of arguments")
+	})
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/crypt"
+)
+
+// The exit codes of run follow env(1).
+const (
+	exitRunFailed = 125 // godenv itself failed
+	exitCannotRun = 126 // the command is found, but cannot be run
+	exitNotFound  = 127 // the command is not found
+)
+
+// forwardedSignals are the signals that are passed to the command instead of terminating godenv.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+func runCommand() *command {
+	return &command{
+		name:    "run",
+		usage:   "[-f file]... [--override] [--only] [--set name=value]... [--unset name]... -- command [arguments]",
+		summary: "Run a command with the variables loaded from the .env files",
+		run:     (*cli).run,
+	}
+}
+
+// runConfig describes how the variables of the files are merged with the current environment.
+type runConfig struct {
+	files    stringsFlag
+	override bool
+	only     bool
+	set      assignmentsFlag
+	unset    stringsFlag
+}
+
+func (c *cli) run(args []string) int {
+	var cfg runConfig
+
+	fs := c.flagSet(runCommand())
+	fs.Var(&cfg.files, "f", "`file` to load, may be repeated: the later files override the earlier ones (default .env)")
+	fs.Var(&cfg.files, "file", "alias for -f")
+	fs.BoolVar(&cfg.override, "override", false, "the variables of the files override the variables of the environment")
+	fs.BoolVar(&cfg.only, "only", false, "pass only the variables of the files, not the environment")
+	fs.Var(&cfg.set, "set", "set the variable, overriding the files and the environment: `name=value`, may be repeated")
+	fs.Var(&cfg.unset, "unset", "remove the variable `name` from the command environment, may be repeated")
+
+	if code, ok := c.parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() == 0 {
+		c.errorf("run: missing command")
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	if len(cfg.files) == 0 {
+		cfg.files = stringsFlag{".env"}
+	}
+
+	vars, err := loadFiles(cfg.files)
+	if err != nil {
+		c.errorf("run: %v", err)
+		return exitRunFailed
+	}
+
+	return c.exec(fs.Args(), mergeEnv(os.Environ(), vars.Map(), cfg))
+}
+
+// exec runs the command, forwards the signals to it and returns its exit code.
+func (c *cli) exec(args, env []string) int {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		c.errorf("run: %v", err)
+		return exitNotFound
+	}
+
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Args[0] = args[0]
+	cmd.Env = env
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		c.errorf("run: %v", err)
+		return exitCannotRun
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			c.errorf("run: %v", err)
+			return exitRunFailed
+		}
+
+		// The command is terminated by a signal: exit with 128+n, as the shells do.
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return 128 + int(ws.Signal())
+		}
+
+		if exitErr.ExitCode() < 0 {
+			return exitError
+		}
+
+		return exitErr.ExitCode()
+	}
+
+	return exitOK
+}
+
+// loadFiles parses the files in order, the variables of the later files override the earlier ones.
+// The values encrypted with the crypt package are decrypted with the default key,
+// the values of the other schemes, such as MODE=enc:plain, are kept as they are written.
+func loadFiles(files []string) (*godenv.Vars, error) {
+	defaultDecrypter := crypt.Decrypter(crypt.DefaultKeyProvider())
+	decrypter := godenv.WithDecrypter(godenv.DecrypterFunc(func(name, value string) (string, error) {
+		if !crypt.IsEncrypted(value) {
+			return value, nil
+		}
+
+		return defaultDecrypter.Decrypt(name, value)
+	}))
+	layers := make([]*godenv.Vars, 0, len(files))
+
+	for _, file := range files {
+		vars, err := godenv.ReadFile(file, decrypter)
+		if err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, vars)
+	}
+
+	return godenv.Merge(layers...), nil
+}
+
+// mergeEnv merges the variables with the environment in the "NAME=value" form.
+// By default, the variables of the environment take precedence over the variables of the files,
+// and the variables set with --set take precedence over both.
+func mergeEnv(environ []string, vars map[string]string, cfg runConfig) []string {
+	merged := make(map[string]string, len(environ)+len(vars))
+
+	if !cfg.only {
+		for _, kv := range environ {
+			if name, value, ok := cutEnv(kv); ok {
+				merged[name] = value
+			}
+		}
+	}
+
+	for name, value := range vars {
+		if _, ok := merged[name]; !ok || cfg.override {
+			merged[name] = value
+		}
+	}
+
+	for name, value := range cfg.set {
+		merged[name] = value
+	}
+
+	for _, name := range cfg.unset {
+		delete(merged, name)
+	}
+
+	env := make([]string, 0, len(merged))
+	for name, value := range merged {
+		env = append(env, name+"="+value)
+	}
+
+	sort.Strings(env)
+
+	return env
+}
+
+// cutEnv splits the "NAME=value" pair. On Windows the names of the hidden variables start with "=".
+func cutEnv(kv string) (string, string, bool) {
+	if kv == "" {
+		return "", "", false
+	}
+
+	i := strings.Index(kv[1:], "=")
+	if i < 0 {
+		return "", "", false
+	}
+
+	return kv[:i+1], kv[i+2:], true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+
+	require.NoError(t, os.WriteFile(base, []byte("GODENV_TEST_HELPER=1\nFOO=base\nBAR=base\nHOME=/base\n"), 0o600))
+	require.NoError(t, os.WriteFile(local, []byte("BAR=local\nGODENV_TEST_EXIT=3\n"), 0o600))
+
+	t.Run("the environment takes precedence", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "run", "-f", base, "-f", local, "--", os.Args[0], "FOO", "BAR", "HOME")
+		assert.Equal(t, 3, code)
+		assert.Equal(t, "FOO=base\nBAR=local\nHOME="+os.Getenv("HOME")+"\n", stdout)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "run", "--file", base, "--override", "--", os.Args[0], "HOME")
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "HOME=/base\n", stdout)
+	})
+
+	t.Run("only", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "run", "-f", base, "--only", "--", os.Args[0], "FOO", "PATH")
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "FOO=base\nPATH is unset\n", stdout)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "run", "-f", base, "--unset", "FOO", "--unset=PATH", "--", os.Args[0], "FOO", "PATH", "BAR")
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "FOO is unset\nPATH is unset\nBAR=base\n", stdout)
+	})
+
+	t.Run("killed by a signal", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the processes are not killed by signals on Windows")
+		}
+
+		code, _, _ := runCLI(t, "", "run", "-f", base, "--set", "GODENV_TEST_KILL=1", "--", os.Args[0])
+		assert.Equal(t, 128+int(syscall.SIGKILL), code)
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "run", "-f", base)
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "godenv: run: missing command\n")
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		code, _, stderr := runCLI(t, "", "run", "-f", base, "--", filepath.Join(dir, "not-found"))
+		assert.Equal(t, exitNotFound, code)
+		assert.Contains(t, stderr, "godenv: run: ")
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.env")
+		require.NoError(t, os.WriteFile(invalid, []byte("FOO= bar\n"), 0o600))
+
+		code, _, stderr := runCLI(t, "", "run", "-f", invalid, "--", os.Args[0])
+		assert.Equal(t, exitRunFailed, code)
+		assert.Equal(t, "godenv: run: "+invalid+":1:5: unexpected token: SPACE( )\n", stderr)
+	})
+}
+
+func TestMergeEnv(t *testing.T) {
+	t.Parallel()
+
+	environ := []string{"FOO=env", "PATH=/bin", "=C:=C:\\", "EMPTY="}
+	vars := map[string]string{"FOO": "file", "BAR": "file"}
+
+	tests := []struct {
+		name     string
+		cfg      runConfig
+		expected []string
+	}{
+		{
+			name:     "environment wins",
+			expected: []string{"=C:=C:\\", "BAR=file", "EMPTY=", "FOO=env", "PATH=/bin"},
+		},
+		{
+			name:     "override",
+			cfg:      runConfig{override: true},
+			expected: []string{"=C:=C:\\", "BAR=file", "EMPTY=", "FOO=file", "PATH=/bin"},
+		},
+		{
+			name:     "only",
+			cfg:      runConfig{only: true},
+			expected: []string{"BAR=file", "FOO=file"},
+		},
+		{
+			name:     "set",
+			cfg:      runConfig{set: assignmentsFlag{"FOO": "flag", "NEW": "a=b"}},
+			expected: []string{"=C:=C:\\", "BAR=file", "EMPTY=", "FOO=flag", "NEW=a=b", "PATH=/bin"},
+		},
+		{
+			name:     "unset",
+			cfg:      runConfig{unset: stringsFlag{"PATH", "BAR"}},
+			expected: []string{"=C:=C:\\", "EMPTY=", "FOO=env"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, mergeEnv(environ, vars, tt.cfg))
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package crypt encrypts the values of the .env files, so the files with the secrets can be committed.
+//
+// An encrypted value looks like DB_PASSWORD=enc:v1:..., it is encrypted with AES-256-GCM, and the name of the
+// variable is authenticated along with the value, so an encrypted value cannot be moved to another variable.
+// The 256-bit key is written in base64, it is read from the GODENV_KEY environment variable or from the .env.key
+// file by default:
+//
+//	vars, err := godenv.ReadFile(".env", godenv.WithDecrypter(crypt.Decrypter(crypt.DefaultKeyProvider())))
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/youla-dev/godenv"
+)
+
+// Prefix marks the values encrypted with this package.
+const Prefix = godenv.EncryptedPrefix + "v1:"
+
+// KeySize is the size of the key in bytes.
+const KeySize = 32
+
+// The default sources of the key.
+const (
+	DefaultKeyEnv  = "GODENV_KEY"
+	DefaultKeyFile = ".env.key"
+)
+
+// The errors of decryption.
+var (
+	ErrUnsupported = errors.New("unsupported encryption scheme")
+	ErrMalformed   = errors.New("malformed encrypted value")
+	ErrDecrypt     = errors.New("message authentication failed: wrong key or tampered value")
+)
+
+// Key is a 256-bit AES key.
+type Key [KeySize]byte
+
+// GenerateKey returns a random key.
+func GenerateKey() (*Key, error) {
+	var key Key
+
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// ParseKey parses the key written in base64, the surrounding whitespace is ignored.
+func ParseKey(s string) (*Key, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != KeySize {
+		return nil, fmt.Errorf("invalid key: must be %d bytes in base64", KeySize)
+	}
+
+	var key Key
+
+	copy(key[:], b)
+
+	return &key, nil
+}
+
+// Encode returns the key in base64.
+func (k *Key) Encode() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// String hides the key, so it is never printed by accident. Use Encode to get the key.
+func (k *Key) String() string {
+	return "crypt.Key(" + godenv.Redacted + ")"
+}
+
+// GoString hides the key, see String.
+func (k *Key) GoString() string {
+	return k.String()
+}
+
+// IsEncrypted reports whether the value is encrypted with this package.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Encrypt encrypts the value of the variable. The result starts with Prefix.
+func (k *Key) Encrypt(name, value string) (string, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	return Prefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value of the variable encrypted with Encrypt.
+// The errors never include the value.
+func (k *Key) Decrypt(name, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", ErrUnsupported
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	aead, err := k.aead()
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return "", ErrMalformed
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plain), nil
+}
+
+func (k *Key) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// KeyProvider supplies the key.
+type KeyProvider interface {
+	Key() (*Key, error)
+}
+
+// KeyProviderFunc is an adapter to allow the use of ordinary functions as KeyProvider.
+type KeyProviderFunc func() (*Key, error)
+
+// Key calls f().
+func (f KeyProviderFunc) Key() (*Key, error) {
+	return f()
+}
+
+// StaticKey returns the provider of the key.
+func StaticKey(key *Key) KeyProvider {
+	return KeyProviderFunc(func() (*Key, error) {
+		return key, nil
+	})
+}
+
+// KeyFile returns the provider that reads the key from the file.
+func KeyFile(path string) KeyProvider {
+	return KeyProviderFunc(func() (*Key, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ParseKey(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		return key, nil
+	})
+}
+
+// KeyEnv returns the provider that reads the key from the environment variable.
+func KeyEnv(name string) KeyProvider {
+	return KeyProviderFunc(func() (*Key, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		key, err := ParseKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+
+		return key, nil
+	})
+}
+
+// DefaultKeyProvider returns the provider that reads the key from the GODENV_KEY environment variable,
+// if it is set, or from the .env.key file in the current directory otherwise.
+func DefaultKeyProvider() KeyProvider {
+	return KeyProviderFunc(func() (*Key, error) {
+		if _, ok := os.LookupEnv(DefaultKeyEnv); ok {
+			return KeyEnv(DefaultKeyEnv).Key()
+		}
+
+		key, err := KeyFile(DefaultKeyFile).Key()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no key: set %s or create %s", DefaultKeyEnv, DefaultKeyFile)
+		}
+
+		return key, err
+	})
+}
+
+// Decrypter returns the godenv.Decrypter that decrypts the values with the key of the provider.
+// The key is requested once, when the first encrypted value is met.
+func Decrypter(provider KeyProvider) godenv.Decrypter {
+	var (
+		once sync.Once
+		key  *Key
+		err  error
+	)
+
+	return godenv.DecrypterFunc(func(name, value string) (string, error) {
+		once.Do(func() {
+			key, err = provider.Key()
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		return key.Decrypt(name, value)
+	})
+}
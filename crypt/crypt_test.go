@@ -0,0 +1,185 @@
+package crypt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/crypt"
+)
+
+func generateKey(t *testing.T) *crypt.Key {
+	t.Helper()
+
+	key, err := crypt.GenerateKey()
+	require.NoError(t, err)
+
+	return key
+}
+
+// setenv sets the environment variable for the duration of the test, like t.Setenv in Go 1.17.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	old, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestKey_Encrypt(t *testing.T) {
+	t.Parallel()
+
+	key := generateKey(t)
+
+	for _, value := range []string{"", "hunter2", "multi\nline 'quoted' \"value\"", strings.Repeat("long", 1000)} {
+		encrypted, err := key.Encrypt("DB_PASSWORD", value)
+		require.NoError(t, err)
+		assert.True(t, crypt.IsEncrypted(encrypted))
+		assert.NotContains(t, encrypted, "hunter2")
+
+		decrypted, err := key.Decrypt("DB_PASSWORD", encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, value, decrypted)
+	}
+
+	first, err := key.Encrypt("A", "value")
+	require.NoError(t, err)
+
+	second, err := key.Encrypt("A", "value")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "the nonce is random")
+}
+
+func TestKey_Decrypt_Errors(t *testing.T) {
+	t.Parallel()
+
+	key := generateKey(t)
+
+	encrypted, err := key.Encrypt("DB_PASSWORD", "hunter2")
+	require.NoError(t, err)
+
+	tampered := []byte(encrypted)
+	if i := len(tampered) / 2; tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+
+	tests := []struct {
+		name     string
+		key      *crypt.Key
+		variable string
+		value    string
+		expected error
+	}{
+		{name: "wrong key", key: generateKey(t), variable: "DB_PASSWORD", value: encrypted, expected: crypt.ErrDecrypt},
+		{name: "moved to another variable", key: key, variable: "API_TOKEN", value: encrypted, expected: crypt.ErrDecrypt},
+		{name: "tampered", key: key, variable: "DB_PASSWORD", value: string(tampered), expected: crypt.ErrDecrypt},
+		{name: "truncated", key: key, variable: "DB_PASSWORD", value: crypt.Prefix + "AAAA", expected: crypt.ErrMalformed},
+		{name: "not base64", key: key, variable: "DB_PASSWORD", value: crypt.Prefix + "hunter2!", expected: crypt.ErrMalformed},
+		{name: "unsupported version", key: key, variable: "DB_PASSWORD", value: "enc:v2:AAAA", expected: crypt.ErrUnsupported},
+	}
+
+	for _, tc := range tests {
+		_, err := tc.key.Decrypt(tc.variable, tc.value)
+		assert.ErrorIs(t, err, tc.expected, tc.name)
+		assert.NotContains(t, err.Error(), "hunter2", tc.name)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	key := generateKey(t)
+
+	parsed, err := crypt.ParseKey("  " + key.Encode() + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	for _, s := range []string{"", "not base64", "c2hvcnQ="} {
+		_, err := crypt.ParseKey(s)
+		assert.EqualError(t, err, "invalid key: must be 32 bytes in base64", s)
+	}
+
+	assert.Equal(t, "crypt.Key(***)", fmt.Sprint(key))
+	assert.Equal(t, "crypt.Key(***)", fmt.Sprintf("%#v", key))
+}
+
+func TestKeyFile(t *testing.T) {
+	t.Parallel()
+
+	key := generateKey(t)
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, ".env.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(key.Encode()+"\n"), 0o600))
+
+	loaded, err := crypt.KeyFile(keyFile).Key()
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	_, err = crypt.KeyFile(filepath.Join(dir, "missing")).Key()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestKeyEnv(t *testing.T) {
+	key := generateKey(t)
+	setenv(t, "TEST_GODENV_KEY", key.Encode())
+
+	loaded, err := crypt.KeyEnv("TEST_GODENV_KEY").Key()
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	_, err = crypt.KeyEnv("TEST_GODENV_KEY_MISSING").Key()
+	assert.EqualError(t, err, "environment variable TEST_GODENV_KEY_MISSING is not set")
+
+	setenv(t, crypt.DefaultKeyEnv, key.Encode())
+
+	loaded, err = crypt.DefaultKeyProvider().Key()
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+}
+
+func TestDecrypter(t *testing.T) {
+	t.Parallel()
+
+	key := generateKey(t)
+
+	encrypted, err := key.Encrypt("DB_PASSWORD", "hunter2")
+	require.NoError(t, err)
+
+	input := "DB_HOST=localhost\nDB_PASSWORD=" + encrypted + "\n"
+
+	vars, err := godenv.Parse(strings.NewReader(input), godenv.WithDecrypter(crypt.Decrypter(crypt.StaticKey(key))))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost", "DB_PASSWORD": "hunter2"}, vars)
+
+	vars, err = godenv.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, vars["DB_PASSWORD"], "the values are kept encrypted without a decrypter")
+
+	_, err = godenv.Parse(strings.NewReader(input), godenv.WithDecrypter(crypt.Decrypter(crypt.StaticKey(generateKey(t)))))
+	assert.EqualError(t, err, "2:1: variable DB_PASSWORD: decrypt: message authentication failed: wrong key or tampered value")
+
+	calls := 0
+	provider := crypt.KeyProviderFunc(func() (*crypt.Key, error) {
+		calls++
+		return key, nil
+	})
+
+	_, err = godenv.Parse(strings.NewReader(input+"API_TOKEN="+encrypted+"\n"), godenv.WithDecrypter(crypt.Decrypter(provider)))
+	assert.Error(t, err, "the value is bound to the variable")
+	assert.Equal(t, 1, calls, "the key is requested once")
+}
@@ -0,0 +1,42 @@
+package godenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncryptedPrefix marks the encrypted values, e.g. DB_PASSWORD=enc:v1:...
+// The part after the prefix names the version of the encryption scheme.
+const EncryptedPrefix = "enc:"
+
+// Decrypter decrypts the encrypted values, see the crypt package for the built-in implementation.
+//
+// The values that start with EncryptedPrefix are decrypted only if a Decrypter is configured
+// with WithDecrypter, otherwise they are kept as they are written.
+type Decrypter interface {
+	// Decrypt returns the plain text of the value assigned to the variable, the value includes EncryptedPrefix.
+	// The error must not include the value.
+	Decrypt(name, value string) (string, error)
+}
+
+// DecrypterFunc is an adapter to allow the use of ordinary functions as Decrypter.
+type DecrypterFunc func(name, value string) (string, error)
+
+// Decrypt calls f(name, value).
+func (f DecrypterFunc) Decrypt(name, value string) (string, error) {
+	return f(name, value)
+}
+
+// decrypt returns the plain text of the value if it is encrypted and a Decrypter is configured.
+func decrypt(name, value string, o *options) (string, error) {
+	if o.decrypter == nil || !strings.HasPrefix(value, EncryptedPrefix) {
+		return value, nil
+	}
+
+	plain, err := o.decrypter.Decrypt(name, value)
+	if err != nil {
+		return "", fmt.Errorf("variable %s: decrypt: %w", name, err)
+	}
+
+	return plain, nil
+}
@@ -0,0 +1,72 @@
+package godenv
+
+import (
+	"sort"
+)
+
+// ChangeKind is a kind of the difference between two sets of variables.
+type ChangeKind int
+
+// The kinds of the changes.
+const (
+	Added ChangeKind = iota + 1
+	Removed
+	Changed
+)
+
+// String returns the name of the kind: "added", "removed" or "changed".
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so the kind is encoded by its name.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change describes a variable that differs between two sets of variables.
+type Change struct {
+	Kind ChangeKind
+	Key  string
+	Old  Variable // the variable of the old set, zero if the variable is added
+	New  Variable // the variable of the new set, zero if the variable is removed
+}
+
+// Diff compares the values of the variables and returns the changes sorted by the key.
+// Only the values are compared: the differences in quoting, comments and order of the variables are ignored.
+func Diff(oldVars, newVars *Vars) []Change {
+	var changes []Change
+
+	for _, key := range oldVars.keys {
+		oldVar := oldVars.vars[key]
+
+		newVar, ok := newVars.vars[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: Removed, Key: key, Old: oldVar})
+		case oldVar.Value != newVar.Value:
+			changes = append(changes, Change{Kind: Changed, Key: key, Old: oldVar, New: newVar})
+		}
+	}
+
+	for _, key := range newVars.keys {
+		if _, ok := oldVars.vars[key]; !ok {
+			changes = append(changes, Change{Kind: Added, Key: key, New: newVars.vars[key]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
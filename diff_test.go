@@ -0,0 +1,48 @@
+package godenv_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+func TestDiff(t *testing.T) {
+	oldVars, err := godenv.Read(strings.NewReader("# staging\nDB_HOST=db.staging\nDEBUG=true\nPORT=\"8080\"\n"))
+	require.NoError(t, err)
+
+	newVars, err := godenv.Read(strings.NewReader("PORT=8080\nDB_HOST='db.prod'\n# new\nSENTRY_DSN=https://sentry\n"))
+	require.NoError(t, err)
+
+	changes := godenv.Diff(oldVars, newVars)
+	assert.Equal(t, []godenv.Change{
+		{
+			Kind: godenv.Changed,
+			Key:  "DB_HOST",
+			Old:  godenv.Variable{Name: "DB_HOST", Value: "db.staging", Pos: godenv.Position{Line: 2, Column: 1}},
+			New:  godenv.Variable{Name: "DB_HOST", Value: "db.prod", Pos: godenv.Position{Line: 2, Column: 1}},
+		},
+		{
+			Kind: godenv.Removed,
+			Key:  "DEBUG",
+			Old:  godenv.Variable{Name: "DEBUG", Value: "true", Pos: godenv.Position{Line: 3, Column: 1}},
+		},
+		{
+			Kind: godenv.Added,
+			Key:  "SENTRY_DSN",
+			New:  godenv.Variable{Name: "SENTRY_DSN", Value: "https://sentry", Pos: godenv.Position{Line: 4, Column: 1}},
+		},
+	}, changes)
+
+	assert.Empty(t, godenv.Diff(oldVars, oldVars))
+}
+
+func TestChangeKind_MarshalText(t *testing.T) {
+	data, err := json.Marshal([]godenv.ChangeKind{godenv.Added, godenv.Removed, godenv.Changed})
+	require.NoError(t, err)
+	assert.Equal(t, `["added","removed","changed"]`, string(data))
+}
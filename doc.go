@@ -44,8 +44,9 @@
 //
 // 	HTTP_LISTEN=":8080"
 // 	LOG_LEVEL="info"
+// 	DB_PASSWORD="s3cr3t"
 //
-// You can easily open the file and parse its content into map[string]string:
+// You can easily open the file and read its content into *godenv.Vars:
 //
 // 	f, err := os.Open(".env")
 // 	if err != nil {
@@ -53,10 +54,15 @@
 // 	}
 // 	defer f.Close()
 //
-// 	vars, err := godenv.Parse(f)
+// 	vars, err := godenv.Read(f)
 // 	if err != nil {
 // 		panic(err)
 // 	}
-// 	fmt.Println(vars)
+// 	fmt.Println(vars.Get("HTTP_LISTEN"))
+//
+// Unlike the map[string]string returned by Parse, Vars is safe to print and log:
+// fmt shows the values of the secrets, such as DB_PASSWORD, as ***:
+//
+// 	fmt.Println(vars) // {HTTP_LISTEN=:8080, LOG_LEVEL=info, DB_PASSWORD=***}
 //
 package godenv
@@ -0,0 +1,84 @@
+package godenv
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrIncludeCycle is returned when a file includes itself, directly or through other files.
+	ErrIncludeCycle = errors.New("include cycle")
+	// ErrIncludeDepth is returned when the include directives are nested deeper than allowed.
+	ErrIncludeDepth = errors.New("include depth limit exceeded")
+	// ErrIncludeNotAllowed is returned for an include directive of the content read from io.Reader,
+	// unless the includes are enabled with WithFS or WithFilename.
+	ErrIncludeNotAllowed = errors.New("include directives are not allowed in the content of io.Reader")
+	// ErrReferenceCycle is returned when the variables of a file reference each other in a cycle,
+	// and the references are expanded in the order of the dependencies, see ExpandInDependencyOrder.
+	ErrReferenceCycle = errors.New("reference cycle")
+)
+
+// Position describes a location in an .env file.
+type Position struct {
+	Filename string `json:"filename,omitempty"` // empty, if the content is read from io.Reader
+	Line     int    `json:"line"`               // 1-based, 0 if the position points to the whole file
+	Column   int    `json:"column"`             // 1-based, counted in bytes
+}
+
+// String returns the position in the "file:line:column" form.
+// The parts that are unknown are omitted.
+func (p Position) String() string {
+	s := p.Filename
+
+	if p.Line > 0 {
+		if s != "" {
+			s += ":"
+		}
+
+		s += strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+	}
+
+	if s == "" {
+		s = "-"
+	}
+
+	return s
+}
+
+// Error describes a problem found in an .env file.
+type Error struct {
+	Pos          Position   // location of the problem
+	IncludedFrom []Position // include directives that led to the file, the innermost first
+	Err          error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Pos.String())
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+
+	for i, pos := range e.IncludedFrom {
+		if i == 0 {
+			b.WriteString(" (included from ")
+		} else {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(pos.String())
+	}
+
+	if len(e.IncludedFrom) > 0 {
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,162 @@
+package godenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// ErrCommandSubstitution is returned when a value contains a $(command) substitution,
+// the commands are rejected, and no CommandRunner is configured.
+var ErrCommandSubstitution = errors.New("command substitution is not allowed")
+
+// CommandRunner runs the commands of $(command) substitutions and returns their output.
+//
+// godenv never runs commands on its own: the substitutions are evaluated only if a CommandRunner
+// is configured with WithCommandRunner.
+type CommandRunner interface {
+	RunCommand(command string) (string, error)
+}
+
+// CommandRunnerFunc is an adapter to allow the use of ordinary functions as CommandRunner.
+type CommandRunnerFunc func(command string) (string, error)
+
+// RunCommand calls f(command).
+func (f CommandRunnerFunc) RunCommand(command string) (string, error) {
+	return f(command)
+}
+
+// evaluate returns the value of the assignment. The variables referenced by the value are looked up by lookup.
+//
+// If the value contains command substitutions, they are replaced with the output of the commands
+// with trailing line breaks removed, as a shell does. Without a CommandRunner the substitutions are kept literal,
+// or ErrCommandSubstitution is returned if the commands are rejected.
+//
+// The variable references are replaced with the values of the variables if the expansion is enabled
+// with WithLookup, otherwise they are kept literal.
+func evaluate(assign *ast.AssignStatement, o *options, lookup func(string) (string, bool)) (string, error) {
+	if !hasSubstitutions(assign.Parts, o) {
+		return assign.Value, nil
+	}
+
+	if hasCommands(assign.Parts) && o.commandRunner == nil && o.rejectCommands {
+		return "", fmt.Errorf("variable %s: %w", assign.Name, ErrCommandSubstitution)
+	}
+
+	var value strings.Builder
+
+	for _, part := range assign.Parts {
+		switch p := part.(type) {
+		case *ast.Text:
+			value.WriteString(p.Value)
+		case *ast.CommandSubstitution:
+			if o.commandRunner == nil {
+				value.WriteString("$(" + p.Command + ")")
+				continue
+			}
+
+			out, err := o.commandRunner.RunCommand(p.Command)
+			if err != nil {
+				if o.debugErrors {
+					return "", fmt.Errorf("variable %s: $(%s): %w", assign.Name, p.Command, err)
+				}
+
+				return "", fmt.Errorf("variable %s: command substitution: %w", assign.Name, err)
+			}
+
+			value.WriteString(strings.TrimRight(out, "\n"))
+		case *ast.VariableReference:
+			value.WriteString(expand(p, o, lookup))
+		}
+	}
+
+	return value.String(), nil
+}
+
+// expand returns the value of the referenced variable, or the reference itself if the expansion is disabled.
+// A variable that is not set expands to an empty string, as in a shell.
+func expand(ref *ast.VariableReference, o *options, lookup func(string) (string, bool)) string {
+	if !o.expand {
+		if ref.HasDefault {
+			return "${" + ref.Name + ":-" + ref.Default + "}"
+		}
+
+		return "${" + ref.Name + "}"
+	}
+
+	value, _ := lookup(ref.Name)
+	if value == "" && ref.HasDefault {
+		return ref.Default
+	}
+
+	return value
+}
+
+// valueOrigin returns the origin and the position of the value of the assignment evaluated with the lookup.
+// The value is the default if it consists of a single reference that falls back to its default,
+// e.g. ${LEVEL:-info} when LEVEL is not set; the position is the position of the reference then.
+func valueOrigin(assign *ast.AssignStatement, o *options, lookup func(string) (string, bool)) (Origin, token.Position) {
+	if !o.expand {
+		return OriginFile, assign.Pos
+	}
+
+	var ref *ast.VariableReference
+
+	for _, part := range assign.Parts {
+		switch p := part.(type) {
+		case *ast.Text:
+			if p.Value != "" {
+				return OriginFile, assign.Pos
+			}
+		case *ast.VariableReference:
+			if ref != nil {
+				return OriginFile, assign.Pos
+			}
+
+			ref = p
+		default:
+			return OriginFile, assign.Pos
+		}
+	}
+
+	if ref == nil || !ref.HasDefault {
+		return OriginFile, assign.Pos
+	}
+
+	if value, _ := lookup(ref.Name); value != "" {
+		return OriginFile, assign.Pos
+	}
+
+	return OriginDefault, ref.Pos
+}
+
+// hasSubstitutions reports whether the parts of the value are to be evaluated.
+func hasSubstitutions(parts []ast.ValuePart, o *options) bool {
+	for _, part := range parts {
+		switch part.(type) {
+		case *ast.CommandSubstitution:
+			if o.commandRunner != nil || o.rejectCommands {
+				return true
+			}
+		case *ast.VariableReference:
+			if o.expand {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasCommands(parts []ast.ValuePart) bool {
+	for _, part := range parts {
+		if _, ok := part.(*ast.CommandSubstitution); ok {
+			return true
+		}
+	}
+
+	return false
+}
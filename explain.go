@@ -0,0 +1,117 @@
+package godenv
+
+import (
+	"strings"
+)
+
+// Origin tells where the value of a variable comes from.
+type Origin int
+
+// The origins of the values.
+const (
+	OriginFile        Origin = iota // assigned in an .env file
+	OriginEnvironment               // taken from the environment of the process
+	OriginDefault                   // the default value, declared in a schema or by ${NAME:-default}
+	OriginOverride                  // set by the application
+)
+
+// String returns the name of the origin: "file", "environment", "default" or "override".
+func (o Origin) String() string {
+	switch o {
+	case OriginFile:
+		return "file"
+	case OriginEnvironment:
+		return "environment"
+	case OriginDefault:
+		return "default"
+	case OriginOverride:
+		return "override"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so the origin is encoded by its name.
+func (o Origin) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// Explanation tells how the variable got its value.
+type Explanation struct {
+	Variable              // the variable that sets the value
+	Overridden []Variable // the variables it overrides, the latest first
+}
+
+// Explain returns the variable along with the variables it overrides: the earlier assignments in the files,
+// and the values of the layers merged with Merge.
+func (v *Vars) Explain(key string) (Explanation, bool) {
+	variable, ok := v.vars[key]
+	if !ok {
+		return Explanation{}, false
+	}
+
+	history := v.history[key]
+	overridden := make([]Variable, 0, len(history))
+
+	for i := len(history) - 1; i >= 0; i-- {
+		overridden = append(overridden, history[i])
+	}
+
+	return Explanation{Variable: variable, Overridden: overridden}, true
+}
+
+// NewVarsOf returns Vars that contains the variables. The later variables override the earlier ones of the same name.
+// The values are redacted according to DefaultRedactPatterns.
+func NewVarsOf(variables ...Variable) *Vars {
+	v := newVars(DefaultRedactPatterns())
+
+	for _, variable := range variables {
+		v.set(variable)
+	}
+
+	return v
+}
+
+// FromEnviron returns the variables of the environment in the "NAME=value" form, such as os.Environ().
+// The entries without "=" are ignored.
+func FromEnviron(environ []string) *Vars {
+	v := newVars(DefaultRedactPatterns())
+
+	for _, entry := range environ {
+		if i := strings.IndexByte(entry, '='); i > 0 {
+			v.set(Variable{Name: entry[:i], Value: entry[i+1:], Origin: OriginEnvironment})
+		}
+	}
+
+	return v
+}
+
+// Merge returns the variables of the layers, the later layers override the earlier ones. The overridden variables
+// are kept, see Explain. The values are redacted according to the patterns of the first layer. Nil layers are skipped.
+func Merge(layers ...*Vars) *Vars {
+	var merged *Vars
+
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = newVars(layer.redact)
+		}
+
+		for _, key := range layer.keys {
+			for _, variable := range layer.history[key] {
+				merged.set(variable)
+			}
+
+			merged.set(layer.vars[key])
+		}
+	}
+
+	if merged == nil {
+		merged = newVars(DefaultRedactPatterns())
+	}
+
+	return merged
+}
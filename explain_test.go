@@ -0,0 +1,91 @@
+package godenv_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+func TestVars_Explain(t *testing.T) {
+	t.Parallel()
+
+	defaults := godenv.NewVarsOf(godenv.Variable{Name: "LOG_LEVEL", Value: "warn", Origin: godenv.OriginDefault})
+	file := readVars(t, "LOG_LEVEL=info\nPORT=80\nLOG_LEVEL=debug\n")
+	env := godenv.FromEnviron([]string{"LOG_LEVEL=error", "=ignored", "INVALID", "EMPTY="})
+
+	vars := godenv.Merge(defaults, nil, file, env)
+
+	assert.Equal(t, []string{"LOG_LEVEL", "PORT", "EMPTY"}, vars.Keys())
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "error", "PORT": "80", "EMPTY": ""}, vars.Map())
+
+	e, ok := vars.Explain("LOG_LEVEL")
+	require.True(t, ok)
+	assert.Equal(t, godenv.Explanation{
+		Variable: godenv.Variable{Name: "LOG_LEVEL", Value: "error", Origin: godenv.OriginEnvironment},
+		Overridden: []godenv.Variable{
+			{Name: "LOG_LEVEL", Value: "debug", Pos: godenv.Position{Line: 3, Column: 1}},
+			{Name: "LOG_LEVEL", Value: "info", Pos: godenv.Position{Line: 1, Column: 1}},
+			{Name: "LOG_LEVEL", Value: "warn", Origin: godenv.OriginDefault},
+		},
+	}, e)
+
+	e, ok = file.Explain("PORT")
+	require.True(t, ok)
+	assert.Equal(t, godenv.Explanation{
+		Variable:   godenv.Variable{Name: "PORT", Value: "80", Pos: godenv.Position{Line: 2, Column: 1}},
+		Overridden: []godenv.Variable{},
+	}, e)
+
+	_, ok = vars.Explain("MISSING")
+	assert.False(t, ok)
+
+	assert.Zero(t, godenv.Merge().Len())
+}
+
+func TestVars_ExplainReferenceDefault(t *testing.T) {
+	t.Parallel()
+
+	input := "LEVEL=${LOG_LEVEL:-info}\nHOST=${HOST:-localhost}\nURL=http://${HOST:-none}\n"
+	lookup := godenv.MapSource{"HOST": "example.com"}.Lookup
+
+	for name, opt := range map[string]godenv.Option{
+		"in order":            godenv.WithLookup(lookup),
+		"in dependency order": godenv.ExpandInDependencyOrder(),
+	} {
+		opt := opt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vars, err := godenv.Read(strings.NewReader(input), opt)
+			require.NoError(t, err)
+
+			e, ok := vars.Explain("LEVEL")
+			require.True(t, ok)
+			assert.Equal(t, godenv.Variable{
+				Name:   "LEVEL",
+				Value:  "info",
+				Pos:    godenv.Position{Line: 1, Column: 7},
+				Origin: godenv.OriginDefault,
+			}, e.Variable, "the value is the default of the reference")
+
+			e, ok = vars.Explain("URL")
+			require.True(t, ok)
+			assert.Equal(t, godenv.OriginFile, e.Origin, "the value is composed in the file")
+			assert.Equal(t, godenv.Position{Line: 3, Column: 1}, e.Pos)
+		})
+	}
+}
+
+func TestOrigin_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal([]godenv.Origin{godenv.OriginFile, godenv.OriginEnvironment, godenv.OriginDefault, godenv.OriginOverride})
+	require.NoError(t, err)
+	assert.Equal(t, `["file","environment","default","override"]`, string(b))
+}
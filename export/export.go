@@ -0,0 +1,107 @@
+// Package export writes the variables of the .env files in the formats of other tools: JSON and YAML, the shells,
+// the Docker --env-file, the $GITHUB_ENV file of GitHub Actions, and the Kubernetes ConfigMap and Secret manifests.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/youla-dev/godenv"
+)
+
+// The names of the formats.
+const (
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatShell      = "shell"
+	FormatFish       = "fish"
+	FormatPowerShell = "powershell"
+	FormatDocker     = "docker"
+	FormatGitHub     = "github"
+	FormatConfigMap  = "configmap"
+	FormatSecret     = "secret"
+)
+
+const defaultName = "env"
+
+// Format is an output format.
+type Format struct {
+	Name        string
+	Description string
+	write       func(w *bufio.Writer, vars *godenv.Vars, o *options) error
+}
+
+// nolint:gochecknoglobals
+var formats = []Format{
+	{Name: FormatJSON, Description: "JSON object", write: writeJSON},
+	{Name: FormatYAML, Description: "YAML mapping", write: writeYAML},
+	{Name: FormatShell, Description: "POSIX shell: export NAME='value'", write: writeShell},
+	{Name: FormatFish, Description: "fish shell: set -gx NAME 'value'", write: writeFish},
+	{Name: FormatPowerShell, Description: "PowerShell: $env:NAME = 'value'", write: writePowerShell},
+	{Name: FormatDocker, Description: "docker run --env-file: NAME=value", write: writeDocker},
+	{Name: FormatGitHub, Description: "GitHub Actions $GITHUB_ENV file", write: writeGitHub},
+	{Name: FormatConfigMap, Description: "Kubernetes ConfigMap manifest", write: writeConfigMap},
+	{Name: FormatSecret, Description: "Kubernetes Secret manifest", write: writeSecret},
+}
+
+// Formats returns all the formats.
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// LookupFormat returns the format by its name.
+func LookupFormat(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// Option configures the output.
+type Option func(*options)
+
+type options struct {
+	name      string
+	namespace string
+}
+
+// Name sets the name of the Kubernetes object, "env" by default.
+func Name(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// Namespace sets the namespace of the Kubernetes object.
+func Namespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// Write writes the variables in the format, in the order of their first assignment.
+// Write returns an error if the format is unknown, or if a name or a value cannot be written in the format,
+// e.g. a name that is not a valid shell identifier.
+func Write(w io.Writer, format string, vars *godenv.Vars, opts ...Option) error {
+	f, ok := LookupFormat(format)
+	if !ok {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	o := &options{name: defaultName}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if err := f.write(bw, vars, o); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,298 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/export"
+)
+
+// nolint:gochecknoglobals
+var values = map[string]string{
+	"PLAIN":     "value",
+	"EMPTY":     "",
+	"QUOTES":    `it's "quoted"`,
+	"BACKSLASH": `C:\path\to\`,
+	"SHELL":     "$HOME `id` $(id) !!",
+	"UNICODE":   "‘curly’ — ünïcödé",
+	"HTML":      "<a href='x'>&amp;</a>",
+	"SPACES":    "  padded  ",
+	"YES":       "yes",
+	"MULTILINE": "first line\nEOF\nlast line\n",
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	vars := godenv.NewVars(map[string]string{"B": "it's", "A": "1", "C": "x\ny"})
+
+	tests := []struct {
+		format   string
+		opts     []export.Option
+		expected string
+	}{
+		{format: export.FormatJSON, expected: "{\n  \"A\": \"1\",\n  \"B\": \"it's\",\n  \"C\": \"x\\ny\"\n}\n"},
+		{format: export.FormatYAML, expected: "A: \"1\"\nB: \"it's\"\nC: \"x\\ny\"\n"},
+		{format: export.FormatShell, expected: "export A='1'\nexport B='it'\\''s'\nexport C='x\ny'\n"},
+		{format: export.FormatFish, expected: "set -gx A '1'\nset -gx B 'it\\'s'\nset -gx C 'x\ny'\n"},
+		{format: export.FormatPowerShell, expected: "$env:A = '1'\n$env:B = 'it''s'\n$env:C = 'x\ny'\n"},
+		{format: export.FormatGitHub, expected: "A=1\nB=it's\nC<<EOF\nx\ny\nEOF\n"},
+		{
+			format:   export.FormatConfigMap,
+			opts:     []export.Option{export.Name("app"), export.Namespace("prod")},
+			expected: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"app\"\n  namespace: \"prod\"\ndata:\n  A: \"1\"\n  B: \"it's\"\n  C: \"x\\ny\"\n",
+		},
+		{
+			format:   export.FormatSecret,
+			expected: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"env\"\ntype: Opaque\ndata:\n  A: MQ==\n  B: aXQncw==\n  C: eAp5\n",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			require.NoError(t, export.Write(&buf, tc.format, vars, tc.opts...))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestWrite_Empty(t *testing.T) {
+	t.Parallel()
+
+	vars := godenv.NewVars(nil)
+
+	for _, format := range export.Formats() {
+		var buf bytes.Buffer
+
+		require.NoError(t, export.Write(&buf, format.Name, vars), format.Name)
+
+		switch format.Name {
+		case export.FormatJSON, export.FormatYAML:
+			assert.Equal(t, "{}\n", buf.String())
+		case export.FormatConfigMap, export.FormatSecret:
+			assert.Contains(t, buf.String(), "data: {}\n")
+		default:
+			assert.Empty(t, buf.String(), format.Name)
+		}
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format   string
+		vars     map[string]string
+		expected string
+	}{
+		{format: "toml", expected: `unknown format "toml"`},
+		{format: export.FormatShell, vars: map[string]string{"my.var": "1"}, expected: "my.var is not a valid shell variable name"},
+		{format: export.FormatFish, vars: map[string]string{"my-var": "1"}, expected: "my-var is not a valid fish variable name"},
+		{format: export.FormatPowerShell, vars: map[string]string{"1VAR": "1"}, expected: "1VAR is not a valid PowerShell variable name"},
+		{format: export.FormatConfigMap, vars: map[string]string{"ключ": "1"}, expected: "ключ is not a valid Kubernetes variable name"},
+		{
+			format:   export.FormatDocker,
+			vars:     map[string]string{"A": "x\ny"},
+			expected: "the value of A contains a line break, it cannot be written in the Docker format",
+		},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+
+		err := export.Write(&buf, tc.format, godenv.NewVars(tc.vars))
+		assert.EqualError(t, err, tc.expected, tc.format)
+		assert.Empty(t, buf.String(), tc.format)
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	singleLine := make(map[string]string)
+
+	for key, value := range values {
+		if !strings.Contains(value, "\n") {
+			singleLine[key] = value
+		}
+	}
+
+	tests := []struct {
+		format string
+		values map[string]string
+		read   func(t *testing.T, out []byte, keys []string) map[string]string
+	}{
+		{format: export.FormatJSON, values: values, read: readJSON},
+		{format: export.FormatYAML, values: values, read: readYAML},
+		{format: export.FormatShell, values: values, read: readShell("sh", `printf '%s\0' "${KEY}"`)},
+		{format: export.FormatFish, values: values, read: readShell("fish", `printf '%s\0' "$KEY"`)},
+		{format: export.FormatPowerShell, values: values, read: readShell("pwsh", `[Console]::Out.Write($env:KEY + [char]0)`)},
+		{format: export.FormatDocker, values: singleLine, read: readDocker},
+		{format: export.FormatGitHub, values: values, read: readGitHub},
+		{format: export.FormatConfigMap, values: values, read: readKubernetes(false)},
+		{format: export.FormatSecret, values: values, read: readKubernetes(true)},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			vars := godenv.NewVars(tc.values)
+
+			var buf bytes.Buffer
+
+			require.NoError(t, export.Write(&buf, tc.format, vars))
+			assert.Equal(t, tc.values, tc.read(t, buf.Bytes(), vars.Keys()))
+		})
+	}
+}
+
+func readJSON(t *testing.T, out []byte, _ []string) map[string]string {
+	t.Helper()
+
+	var result map[string]string
+
+	require.NoError(t, json.Unmarshal(out, &result))
+
+	return result
+}
+
+func readYAML(t *testing.T, out []byte, _ []string) map[string]string {
+	t.Helper()
+
+	var result map[string]string
+
+	require.NoError(t, yaml.Unmarshal(out, &result))
+
+	return result
+}
+
+// readShell runs the script with the shell, and then prints the values separated with NUL by the print command,
+// in which KEY is replaced with the name of the variable.
+func readShell(shell, print string) func(t *testing.T, out []byte, keys []string) map[string]string {
+	return func(t *testing.T, out []byte, keys []string) map[string]string {
+		t.Helper()
+
+		path, err := exec.LookPath(shell)
+		if err != nil {
+			t.Skipf("%s is not installed", shell)
+		}
+
+		script := string(out)
+		for _, key := range keys {
+			script += "\n" + strings.ReplaceAll(print, "KEY", key)
+		}
+
+		file := filepath.Join(t.TempDir(), "script")
+		require.NoError(t, os.WriteFile(file, []byte(script), 0o600))
+
+		printed, err := exec.Command(path, file).Output() // nolint:gosec
+		require.NoError(t, err)
+
+		result := make(map[string]string)
+		for i, value := range strings.Split(strings.TrimSuffix(string(printed), "\x00"), "\x00") {
+			result[keys[i]] = value
+		}
+
+		return result
+	}
+}
+
+func readDocker(t *testing.T, out []byte, _ []string) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		i := strings.IndexByte(scanner.Text(), '=')
+		require.Positive(t, i)
+
+		result[scanner.Text()[:i]] = scanner.Text()[i+1:]
+	}
+
+	return result
+}
+
+// readGitHub reads the file the way the runner of GitHub Actions does.
+func readGitHub(t *testing.T, out []byte, _ []string) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string)
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if eq := strings.IndexByte(line, '='); eq >= 0 && !strings.Contains(line[:eq], "<<") {
+			result[line[:eq]] = line[eq+1:]
+			continue
+		}
+
+		heredoc := strings.Index(line, "<<")
+		require.Positive(t, heredoc, line)
+
+		key, delimiter := line[:heredoc], line[heredoc+2:]
+
+		var value []string
+
+		for i++; lines[i] != delimiter; i++ {
+			value = append(value, lines[i])
+		}
+
+		result[key] = strings.Join(value, "\n")
+	}
+
+	return result
+}
+
+func readKubernetes(secret bool) func(t *testing.T, out []byte, keys []string) map[string]string {
+	return func(t *testing.T, out []byte, _ []string) map[string]string {
+		t.Helper()
+
+		var manifest struct {
+			APIVersion string            `yaml:"apiVersion"`
+			Kind       string            `yaml:"kind"`
+			Data       map[string]string `yaml:"data"`
+		}
+
+		require.NoError(t, yaml.Unmarshal(out, &manifest))
+		assert.Equal(t, "v1", manifest.APIVersion)
+
+		if !secret {
+			assert.Equal(t, "ConfigMap", manifest.Kind)
+			return manifest.Data
+		}
+
+		assert.Equal(t, "Secret", manifest.Kind)
+
+		for key, value := range manifest.Data {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			require.NoError(t, err)
+
+			manifest.Data[key] = string(decoded)
+		}
+
+		return manifest.Data
+	}
+}
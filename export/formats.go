@@ -0,0 +1,211 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/printer"
+)
+
+// nolint:gochecknoglobals
+var (
+	shellName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	kubernetesKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	yamlPlain     = regexp.MustCompile(`^[A-Za-z_][-A-Za-z0-9_.]*$`)
+
+	shellQuoter      = strings.NewReplacer(`'`, `'\''`)
+	fishQuoter       = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	powerShellQuoter = strings.NewReplacer(`'`, `''`, "‘", "‘‘", "’", "’’",
+		"‚", "‚‚", "‛", "‛‛")
+)
+
+func writeJSON(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	if vars.Len() == 0 {
+		w.WriteString("{}\n")
+		return nil
+	}
+
+	w.WriteString("{\n")
+
+	for i, key := range vars.Keys() {
+		fmt.Fprintf(w, "  %s: %s", jsonString(key), jsonString(vars.Get(key)))
+
+		if i < vars.Len()-1 {
+			w.WriteByte(',')
+		}
+
+		w.WriteByte('\n')
+	}
+
+	w.WriteString("}\n")
+
+	return nil
+}
+
+func writeYAML(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	if vars.Len() == 0 {
+		w.WriteString("{}\n")
+		return nil
+	}
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "%s: %s\n", yamlKey(key), jsonString(vars.Get(key)))
+	}
+
+	return nil
+}
+
+func writeShell(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	if err := checkNames(vars, shellName, "shell"); err != nil {
+		return err
+	}
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "export %s='%s'\n", key, shellQuoter.Replace(vars.Get(key)))
+	}
+
+	return nil
+}
+
+func writeFish(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	if err := checkNames(vars, shellName, "fish"); err != nil {
+		return err
+	}
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "set -gx %s '%s'\n", key, fishQuoter.Replace(vars.Get(key)))
+	}
+
+	return nil
+}
+
+func writePowerShell(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	if err := checkNames(vars, shellName, "PowerShell"); err != nil {
+		return err
+	}
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "$env:%s = '%s'\n", key, powerShellQuoter.Replace(vars.Get(key)))
+	}
+
+	return nil
+}
+
+// writeDocker writes the values as they are: Docker reads the rest of the line after "=" as the value,
+// without unquoting, so the values cannot contain line breaks.
+func writeDocker(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	for _, key := range vars.Keys() {
+		if strings.ContainsAny(vars.Get(key), "\r\n") {
+			return fmt.Errorf("the value of %s contains a line break, it cannot be written in the Docker format", key)
+		}
+	}
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "%s=%s\n", key, vars.Get(key))
+	}
+
+	return nil
+}
+
+// writeGitHub writes the multi-line values in the NAME<<DELIMITER form, the delimiter does not clash with
+// any line of the value.
+func writeGitHub(w *bufio.Writer, vars *godenv.Vars, _ *options) error {
+	for _, key := range vars.Keys() {
+		value := vars.Get(key)
+
+		if !strings.ContainsAny(value, "\r\n") {
+			fmt.Fprintf(w, "%s=%s\n", key, value)
+			continue
+		}
+
+		delimiter := printer.Delimiter(strings.ReplaceAll(value, "\r", ""))
+		fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+	}
+
+	return nil
+}
+
+func writeConfigMap(w *bufio.Writer, vars *godenv.Vars, o *options) error {
+	return writeKubernetes(w, vars, o, "ConfigMap", jsonString)
+}
+
+func writeSecret(w *bufio.Writer, vars *godenv.Vars, o *options) error {
+	return writeKubernetes(w, vars, o, "Secret", func(value string) string {
+		if value == "" {
+			return `""` // an empty plain scalar is null
+		}
+
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	})
+}
+
+func writeKubernetes(w *bufio.Writer, vars *godenv.Vars, o *options, kind string, encode func(string) string) error {
+	if err := checkNames(vars, kubernetesKey, "Kubernetes"); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, jsonString(o.name))
+
+	if o.namespace != "" {
+		fmt.Fprintf(w, "  namespace: %s\n", jsonString(o.namespace))
+	}
+
+	if kind == "Secret" {
+		w.WriteString("type: Opaque\n")
+	}
+
+	if vars.Len() == 0 {
+		w.WriteString("data: {}\n")
+		return nil
+	}
+
+	w.WriteString("data:\n")
+
+	for _, key := range vars.Keys() {
+		fmt.Fprintf(w, "  %s: %s\n", yamlKey(key), encode(vars.Get(key)))
+	}
+
+	return nil
+}
+
+// checkNames returns an error if a name does not match the pattern of the format.
+func checkNames(vars *godenv.Vars, pattern *regexp.Regexp, format string) error {
+	for _, key := range vars.Keys() {
+		if !pattern.MatchString(key) {
+			return fmt.Errorf("%s is not a valid %s variable name", key, format)
+		}
+	}
+
+	return nil
+}
+
+// jsonString returns the string as a JSON string, which is also a valid YAML double-quoted scalar.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // a string is always encoded
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yamlKey returns the key unquoted, unless YAML would read it as something else than the string.
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "y", "n", "yes", "no", "on", "off", "true", "false", "null":
+		return jsonString(key)
+	}
+
+	if !yamlPlain.MatchString(key) {
+		return jsonString(key)
+	}
+
+	return key
+}
@@ -0,0 +1,278 @@
+// Package format implements the canonical formatting of the .env files.
+//
+// The canonical form keeps the variables, the comments and the include directives in place, and:
+//   - writes every value in the most readable notation: unquoted, in single quotes if no escape sequences
+//     are needed, in double quotes, or as a heredoc;
+//   - strips the trailing whitespace of the lines and of the unquoted values;
+//   - removes the leading and the trailing blank lines, and collapses the consecutive blank lines into one;
+//   - optionally sorts the keys within the groups delimited by blank lines and comments.
+//
+// A value that cannot be written in any notation, e.g. a value with substitutions that contains
+// both double quotes and text, keeps the text of its assignment as it is written.
+//
+// Formatting of a file in the canonical form produces the same file.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+const whitespace = " \t\r\v\f"
+
+// Option configures the formatting.
+type Option func(*options)
+
+type options struct {
+	sortKeys bool
+}
+
+// SortKeys sorts the keys within the groups of assignments delimited by blank lines and comments.
+// An assignment is not moved across the assignments of the same key, nor across the assignments it references
+// with ${NAME} or that reference it, so the references expand to the same values.
+func SortKeys() Option {
+	return func(o *options) {
+		o.sortKeys = true
+	}
+}
+
+// Source formats the content of the .env file in the canonical form.
+// If the content cannot be parsed, Source returns *godenv.Error with the position of the problem.
+func Source(src []byte, opts ...Option) ([]byte, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if !utf8.Valid(src) {
+		return nil, &godenv.Error{Pos: godenv.Position{Line: 1, Column: 1}, Err: errors.New("illegal UTF-8 encoding")}
+	}
+
+	statement, err := parser.New(scanner.New(string(src))).Parse()
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			pos := godenv.Position{Line: syntaxErr.Pos.Line, Column: syntaxErr.Pos.Column}
+			return nil, &godenv.Error{Pos: pos, Err: errors.New(syntaxErr.SafeMsg)}
+		}
+
+		return nil, err
+	}
+
+	file, ok := statement.(*ast.FileStatement)
+	if !ok {
+		return nil, errors.New("unexpected statement")
+	}
+
+	verbatim := verbatimStatements(string(src), file.Statements)
+	statements := normalize(file.Statements, verbatim)
+
+	if o.sortKeys {
+		sortKeys(statements)
+	}
+
+	var buf bytes.Buffer
+
+	for _, stmt := range statements {
+		if text, ok := verbatim[stmt]; ok {
+			buf.WriteString(text)
+		} else if err := printer.Fprint(&buf, stmt); err != nil {
+			return nil, err
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// verbatimStatements returns the text of the assignments that the printer cannot write, as they are written
+// in the source, without the trailing whitespace.
+func verbatimStatements(src string, statements []ast.Statement) map[ast.Statement]string {
+	verbatim := make(map[ast.Statement]string)
+
+	var lines []string
+
+	for i, stmt := range statements {
+		s, ok := stmt.(*ast.AssignStatement)
+		if !ok || printer.Fprint(io.Discard, s) == nil {
+			continue
+		}
+
+		if lines == nil {
+			lines = strings.SplitAfter(src, "\n")
+		}
+
+		end := len(lines)
+		if i+1 < len(statements) {
+			end = line(statements[i+1]) - 1
+		}
+
+		verbatim[s] = strings.TrimRight(strings.Join(lines[s.Pos.Line-1:end], ""), whitespace+"\n")
+	}
+
+	return verbatim
+}
+
+// normalize strips the trailing whitespace and the redundant blank lines.
+func normalize(statements []ast.Statement, verbatim map[ast.Statement]string) []ast.Statement {
+	result := make([]ast.Statement, 0, len(statements))
+
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *ast.BlankLineStatement:
+			if len(result) == 0 || isBlankLine(result[len(result)-1]) {
+				continue
+			}
+		case *ast.CommentStatement:
+			stmt = &ast.CommentStatement{Value: strings.TrimRight(s.Value, whitespace), Pos: s.Pos}
+		case *ast.AssignStatement:
+			if _, ok := verbatim[s]; !ok && !s.Quoted {
+				stmt = trimValue(s)
+			}
+		}
+
+		result = append(result, stmt)
+	}
+
+	for len(result) > 0 && isBlankLine(result[len(result)-1]) {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// trimValue strips the trailing whitespace of the unquoted value.
+func trimValue(s *ast.AssignStatement) *ast.AssignStatement {
+	trimmed := *s
+	trimmed.Value = strings.TrimRight(s.Value, whitespace)
+
+	if n := len(s.Parts); n > 0 {
+		if text, ok := s.Parts[n-1].(*ast.Text); ok {
+			trimmed.Parts = append([]ast.ValuePart(nil), s.Parts[:n-1]...)
+
+			if value := strings.TrimRight(text.Value, whitespace); value != "" {
+				trimmed.Parts = append(trimmed.Parts, &ast.Text{Value: value})
+			}
+		}
+	}
+
+	return &trimmed
+}
+
+// sortKeys sorts the assignments within the groups of consecutive assignments.
+func sortKeys(statements []ast.Statement) {
+	for start := 0; start < len(statements); {
+		end := start
+		for end < len(statements) && name(statements[end]) != "" {
+			end++
+		}
+
+		sortGroup(statements[start:end])
+
+		start = end + 1
+	}
+}
+
+// sortGroup sorts the assignments of the group by the keys, keeping the order of the dependent assignments:
+// the next one is the assignment with the least key among the ones whose dependencies are placed.
+func sortGroup(group []ast.Statement) {
+	sorted := make([]ast.Statement, 0, len(group))
+	placed := make([]bool, len(group))
+
+	for len(sorted) < len(group) {
+		next := -1
+
+		for i := range group {
+			if placed[i] || !ready(group, placed, i) {
+				continue
+			}
+
+			if next < 0 || name(group[i]) < name(group[next]) {
+				next = i
+			}
+		}
+
+		placed[next] = true
+		sorted = append(sorted, group[next])
+	}
+
+	copy(group, sorted)
+}
+
+// ready reports whether the assignments that must precede the assignment i are placed.
+func ready(group []ast.Statement, placed []bool, i int) bool {
+	for j := 0; j < i; j++ {
+		if !placed[j] && dependent(group[j], group[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dependent reports whether the order of the assignments matters: they assign the same key,
+// or one of them references the key of the other.
+func dependent(a, b ast.Statement) bool {
+	return name(a) == name(b) || references(a, name(b)) || references(b, name(a))
+}
+
+// references reports whether the value of the assignment references the variable.
+func references(stmt ast.Statement, key string) bool {
+	s, ok := stmt.(*ast.AssignStatement)
+	if !ok {
+		return false
+	}
+
+	for _, part := range s.Parts {
+		if ref, ok := part.(*ast.VariableReference); ok && ref.Name == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// name returns the name of the variable assigned by the statement, or an empty string for other statements.
+func name(stmt ast.Statement) string {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		return s.Name
+	case *ast.HeredocStatement:
+		return s.Name
+	default:
+		return ""
+	}
+}
+
+// line returns the line of the statement.
+func line(stmt ast.Statement) int {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		return s.Pos.Line
+	case *ast.HeredocStatement:
+		return s.Pos.Line
+	case *ast.CommentStatement:
+		return s.Pos.Line
+	case *ast.IncludeStatement:
+		return s.Pos.Line
+	case *ast.BlankLineStatement:
+		return s.Pos.Line
+	default:
+		return 0
+	}
+}
+
+func isBlankLine(stmt ast.Statement) bool {
+	_, ok := stmt.(*ast.BlankLineStatement)
+	return ok
+}
@@ -0,0 +1,203 @@
+package format_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/format"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []format.Option
+		expected string
+	}{
+		{
+			name:     "empty file",
+			input:    "\n\n",
+			expected: "",
+		},
+		{
+			name:     "canonical file",
+			input:    "# Database\nDB_HOST=localhost\nDB_PASSWORD='pa$$word'\n\n# HTTP\nHTTP_ADDR=:8080\n",
+			expected: "# Database\nDB_HOST=localhost\nDB_PASSWORD='pa$$word'\n\n# HTTP\nHTTP_ADDR=:8080\n",
+		},
+		{
+			name:     "quoting",
+			input:    "PLAIN=\"value\"\nSPACE=\"a b\"\nESCAPE='a\\nb'\nNEWLINE=\"a\\nb\"\nNAKED\n",
+			expected: "PLAIN=value\nSPACE='a b'\nESCAPE='a\\nb'\nNEWLINE=\"a\\nb\"\nNAKED=\n",
+		},
+		{
+			name:     "trailing whitespace",
+			input:    "# comment \t\nUNQUOTED=value  \nQUOTED='value  '\nCOMMAND=$(date) \nCRLF=value\r\n",
+			expected: "# comment\nUNQUOTED=value\nQUOTED='value  '\nCOMMAND=\"$(date)\"\nCRLF=value\n",
+		},
+		{
+			name:     "blank lines",
+			input:    "\n\n  \nA=1\n\n\n \t\nB=2\n\n\n",
+			expected: "A=1\n\nB=2\n",
+		},
+		{
+			name:     "heredoc and include",
+			input:    "#include   \"common.env\"\nJSON<<~JSON\n    {\n      \"a\": 1\n    }\n    JSON\n",
+			expected: "#include common.env\nJSON<<~JSON\n  {\n    \"a\": 1\n  }\n  JSON\n",
+		},
+		{
+			name:     "value that cannot be quoted",
+			input:    "A=x\"${B}\"  \nB=1\nC=x\"$(date)\" \\\n  y\n\n",
+			expected: "A=x\"${B}\"\nB=1\nC=x\"$(date)\" \\\n  y\n",
+		},
+		{
+			name:     "unsorted keys are kept",
+			input:    "B=1\nA=2\n",
+			expected: "B=1\nA=2\n",
+		},
+		{
+			name:     "sorted keys with references",
+			input:    "C=1\nB=${A}\nA=1\nD=\"${C}\"\n",
+			opts:     []format.Option{format.SortKeys()},
+			expected: "B=\"${A}\"\nA=1\nC=1\nD=\"${C}\"\n",
+		},
+		{
+			name:     "sorted keys",
+			input:    "C=1\nB<<EOF\nb\nEOF\nA=3\nC=4\n# group\nZ=5\nY=6\n\nX=7\nW=8\n",
+			opts:     []format.Option{format.SortKeys()},
+			expected: "A=3\nB<<EOF\nb\nEOF\nC=1\nC=4\n# group\nY=6\nZ=5\n\nW=8\nX=7\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := format.Source([]byte(tt.input), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+
+			again, err := format.Source(actual, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, string(actual), string(again), "formatting is not idempotent")
+		})
+	}
+}
+
+// corpus holds the inputs of the parser tests, and the values that the printer cannot write.
+var corpus = []string{
+	"name=value",
+	`name="value"`,
+	`name='value'`,
+	"name=",
+	"name",
+	"\n\n\n\nname=\n\n\n",
+	"\n\n\n\nname=\n\n  \n",
+	"DEBUG_HTTP_ADDR=:9090\nDEBUG_HTTP_IDLE_TIMEOUT=0s\nJAEGER_AGENT_ENDPOINT=jaeger-otlp-agent:6831",
+	"# comment 1\nDEBUG_HTTP_ADDR=:9090\n# comment 2",
+	`FOO="bar\nbaz"`,
+	`FOO=bar\nbaz`,
+	`FOO="'d'"`,
+	`FOO=foobar=`,
+	`FOO=bar # this is foo`,
+	`FOO="bar#baz"`,
+	"JVM_OPTS=-Xms512m \\\n-Xmx2g\nLOG_LEVEL=info",
+	"JVM_OPTS=\"-Xms512m \\\n-Xmx2g\"",
+	"KEY<<EOF\n{\"type\": \"service_account\"}\nEOF\nNEXT=value",
+	"KEY<<~JSON\n  {\n    \"a\": 1\n  }\n  JSON",
+	"FOO=<<EOF",
+	`GIT_SHA="sha-$(git rev-parse HEAD)"`,
+	`URL="http://${HOST}:${PORT:-8080}/$(whoami)"`,
+	`BUILD=$(date)\t$(whoami)`,
+	"#include ./common.env\nFOO=bar\n#include \"local env/.env\"",
+	"#includes are resolved first\n",
+	"# include ./common.env\n",
+	`FOO='bar#baz'`,
+	`A=x"${B}"`,
+	"A=x\"${B}\" \\\n  y\nB=2 \n",
+	`A="it's \n${B}"`,
+	`A=${B:-"x"}`,
+	`A=${B:-it's}`,
+	`A=${B:-a\tb}`,
+	`A="${B:-a\nb}"`,
+}
+
+func TestSource_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range corpus {
+		input := input
+
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			once, err := format.Source([]byte(input))
+			require.NoError(t, err)
+
+			twice, err := format.Source(once)
+			require.NoError(t, err)
+			assert.Equal(t, string(once), string(twice))
+		})
+	}
+}
+
+func TestSource_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range corpus {
+		input := input
+
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := format.Source([]byte(input))
+			require.NoError(t, err)
+
+			for _, opts := range [][]godenv.Option{nil, {godenv.WithLookup(nil)}} {
+				expected, err := godenv.Parse(strings.NewReader(input), opts...)
+				if err != nil {
+					_, err = godenv.Parse(bytes.NewReader(formatted), opts...)
+					assert.Error(t, err, "the formatted file fails to load as the input does")
+
+					continue
+				}
+
+				actual, err := godenv.Parse(bytes.NewReader(formatted), opts...)
+				require.NoError(t, err)
+				assert.Equal(t, trimValues(expected), trimValues(actual), "the formatted file has the same variables")
+			}
+		})
+	}
+}
+
+// trimValues strips the trailing whitespace of the values, as the formatting does for the unquoted values.
+func trimValues(vars map[string]string) map[string]string {
+	trimmed := make(map[string]string, len(vars))
+	for name, value := range vars {
+		trimmed[name] = strings.TrimRight(value, " \t")
+	}
+
+	return trimmed
+}
+
+func TestSource_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := format.Source([]byte("FOO=bar\nBAR= baz\n"))
+	require.EqualError(t, err, "2:5: unexpected token: SPACE( )")
+
+	var parseErr *godenv.Error
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, godenv.Position{Line: 2, Column: 5}, parseErr.Pos)
+
+	_, err = format.Source([]byte("A=x\uFEFF\n"))
+	require.EqualError(t, err, "1:4: unexpected token: Illegal")
+}
@@ -1,42 +1,40 @@
 package godenv
 
 import (
-	"fmt"
 	"io"
-	"io/ioutil"
-
-	"github.com/youla-dev/godenv/internal/ast"
-	"github.com/youla-dev/godenv/internal/parser"
-	"github.com/youla-dev/godenv/internal/scanner"
+	"io/fs"
 )
 
 // Parse reads an env file from io.Reader, returning a map of keys and values.
-func Parse(r io.Reader) (map[string]string, error) {
-	input, err := ioutil.ReadAll(r)
+//
+// The include directives are rejected with ErrIncludeNotAllowed, unless they are resolved against the root
+// of the file system configured with WithFS, or against the directory of the file named with WithFilename.
+func Parse(r io.Reader, opts ...Option) (map[string]string, error) {
+	vars, err := Read(r, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	s := scanner.New(string(input))
-	p := parser.New(s)
+	return vars.Map(), nil
+}
 
-	statement, err := p.Parse()
+// ParseFile reads an env file, returning a map of keys and values.
+//
+// The include directives are resolved against the directory of the file that contains them.
+// The file is read from the file system configured with WithFS, or from the operating system.
+func ParseFile(filename string, opts ...Option) (map[string]string, error) {
+	vars, err := ReadFile(filename, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	fileStmt, ok := statement.(*ast.FileStatement)
-	if !ok {
-		return nil, fmt.Errorf("unexpected statement: %T", statement)
-	}
-
-	values := make(map[string]string, len(fileStmt.Statements))
-
-	for _, stmt := range fileStmt.Statements {
-		if assign, ok := stmt.(*ast.AssignStatement); ok {
-			values[assign.Name] = assign.Value
-		}
-	}
+	return vars.Map(), nil
+}
 
-	return values, nil
+// ParseFS reads an env file from fsys, returning a map of keys and values.
+// The name is slash-separated, as required by fs.FS, e.g. a path inside embed.FS.
+//
+// ParseFS is a shortcut for ParseFile with the WithFS option.
+func ParseFS(fsys fs.FS, name string, opts ...Option) (map[string]string, error) {
+	return ParseFile(name, append(opts, WithFS(fsys))...)
 }
@@ -3,6 +3,7 @@ package godenv_test
 import (
 	"bytes"
 	"fmt"
+	"testing/fstest"
 
 	"github.com/youla-dev/godenv"
 )
@@ -24,3 +25,41 @@ VARIABLE_TAB_2="Tab is escaped\t"`
 	// Output:
 	// map[VARIABLE_1:This is variable 1 VARIABLE_2:This is variable 2 VARIABLE_TAB_1:Tab is not escaped\t VARIABLE_TAB_2:Tab is escaped	]
 }
+
+func ExampleMarshal() {
+	vars := map[string]string{
+		"HTTP_ADDR":          ":8080",
+		"GREETING":           "Hello, world!",
+		"GOOGLE_CREDENTIALS": "{\n  \"type\": \"service_account\"\n}",
+	}
+
+	result, err := godenv.Marshal(vars)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(result)
+	// Output:
+	// GOOGLE_CREDENTIALS<<EOF
+	// {
+	//   "type": "service_account"
+	// }
+	// EOF
+	// GREETING='Hello, world!'
+	// HTTP_ADDR=:8080
+}
+
+func ExampleParseFS() {
+	// An embed.FS works the same way
+	fsys := fstest.MapFS{
+		"config/.env":         {Data: []byte("#include defaults.env\nHTTP_ADDR=:8080\n")},
+		"config/defaults.env": {Data: []byte("HTTP_ADDR=:80\nLOG_LEVEL=info\n")},
+	}
+
+	result, err := godenv.ParseFS(fsys, "config/.env")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result)
+	// Output:
+	// map[HTTP_ADDR::8080 LOG_LEVEL:info]
+}
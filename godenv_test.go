@@ -2,7 +2,12 @@ package godenv_test
 
 import (
 	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,3 +36,517 @@ WAIT_BEFORE_EXIT=0s`
 	require.NoError(t, err)
 	assert.Equal(t, expected, values)
 }
+
+func TestParse_LineContinuation(t *testing.T) {
+	raw := `JVM_OPTS="-Xms512m \
+    -Xmx2g \
+    -XX:+UseG1GC"
+JAVA_HOME=/usr/lib/jvm/\
+java-17`
+
+	t.Run("indentation is kept", func(t *testing.T) {
+		values, err := godenv.Parse(bytes.NewBufferString(raw))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"JVM_OPTS":  "-Xms512m     -Xmx2g     -XX:+UseG1GC",
+			"JAVA_HOME": "/usr/lib/jvm/java-17",
+		}, values)
+	})
+
+	t.Run("indentation is trimmed", func(t *testing.T) {
+		values, err := godenv.Parse(bytes.NewBufferString(raw), godenv.TrimContinuationIndent())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"JVM_OPTS":  "-Xms512m -Xmx2g -XX:+UseG1GC",
+			"JAVA_HOME": "/usr/lib/jvm/java-17",
+		}, values)
+	})
+}
+
+func TestParse_Heredoc(t *testing.T) {
+	raw := `GOOGLE_CREDENTIALS<<~JSON
+  {
+    "type": "service_account",
+    "project_id": "godenv"
+  }
+  JSON
+LOG_LEVEL=debug`
+
+	values, err := godenv.Parse(bytes.NewBufferString(raw))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"GOOGLE_CREDENTIALS": "{\n  \"type\": \"service_account\",\n  \"project_id\": \"godenv\"\n}",
+		"LOG_LEVEL":          "debug",
+	}, values)
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		vars := map[string]string{
+			"HTTP_ADDR":          ":80",
+			"GREETING":           "Hello, world!",
+			"MULTILINE":          "line 1\nline 2",
+			"PATTERN":            `^\d+$`,
+			"GOOGLE_CREDENTIALS": "{\n  \"type\": \"service_account\"\n}",
+			"CRLF":               "line 1\r\nline 2\r\n",
+			"EMPTY":              "",
+		}
+
+		raw, err := godenv.Marshal(vars)
+		require.NoError(t, err)
+
+		values, err := godenv.Parse(bytes.NewBufferString(raw))
+		require.NoError(t, err)
+		assert.Equal(t, vars, values)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := godenv.Marshal(map[string]string{"INVALID NAME": "value"})
+		require.Error(t, err)
+	})
+
+	t.Run("value that cannot be written", func(t *testing.T) {
+		_, err := godenv.Marshal(map[string]string{"CRLF": "x\r\ny'\""})
+		require.EqualError(t, err, "variable CRLF: the value cannot be written")
+	})
+}
+
+func TestParse_CommandSubstitution(t *testing.T) {
+	raw := `GIT_SHA=$(git rev-parse HEAD)
+VERSION="v1.2.3-$(git rev-parse --short HEAD)"
+LITERAL='$(git rev-parse HEAD)'`
+
+	t.Run("commands are not run by default", func(t *testing.T) {
+		values, err := godenv.Parse(bytes.NewBufferString(raw))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"GIT_SHA": "$(git rev-parse HEAD)",
+			"VERSION": "v1.2.3-$(git rev-parse --short HEAD)",
+			"LITERAL": "$(git rev-parse HEAD)",
+		}, values)
+	})
+
+	t.Run("commands are rejected", func(t *testing.T) {
+		values, err := godenv.Parse(bytes.NewBufferString(raw), godenv.RejectCommandSubstitution())
+		require.ErrorIs(t, err, godenv.ErrCommandSubstitution)
+		assert.Nil(t, values)
+	})
+
+	t.Run("commands are run by the runner", func(t *testing.T) {
+		var commands []string
+
+		runner := godenv.CommandRunnerFunc(func(command string) (string, error) {
+			commands = append(commands, command)
+
+			switch command {
+			case "git rev-parse HEAD":
+				return "4fbc1a2d\n\n", nil
+			case "git rev-parse --short HEAD":
+				return "4fbc1a2\n", nil
+			default:
+				return "", errors.New("unexpected command")
+			}
+		})
+
+		values, err := godenv.Parse(
+			bytes.NewBufferString(raw),
+			godenv.WithCommandRunner(runner),
+			godenv.RejectCommandSubstitution(),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"GIT_SHA": "4fbc1a2d",
+			"VERSION": "v1.2.3-4fbc1a2",
+			"LITERAL": "$(git rev-parse HEAD)",
+		}, values)
+		assert.Equal(t, []string{"git rev-parse HEAD", "git rev-parse --short HEAD"}, commands)
+	})
+
+	t.Run("runner error", func(t *testing.T) {
+		errFailed := errors.New("exit status 128")
+
+		runner := godenv.CommandRunnerFunc(func(command string) (string, error) {
+			return "", errFailed
+		})
+
+		values, err := godenv.Parse(bytes.NewBufferString(raw), godenv.WithCommandRunner(runner))
+		require.ErrorIs(t, err, errFailed)
+		assert.Contains(t, err.Error(), "GIT_SHA")
+		assert.Nil(t, values)
+	})
+}
+
+func TestParse_VariableExpansion(t *testing.T) {
+	t.Parallel()
+
+	lookup := godenv.MapSource{"HOME": "/home/gopher", "PATH": "/usr/bin", "EMPTY": ""}.Lookup
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []godenv.Option
+		expected map[string]string
+	}{
+		{
+			name:     "references are kept literal by default",
+			input:    "DIR=${HOME}/app\nBIN=\"${DIR:-/opt}/bin\"\n",
+			expected: map[string]string{"DIR": "${HOME}/app", "BIN": "${DIR:-/opt}/bin"},
+		},
+		{
+			name:     "nil lookup expands only the variables of the file",
+			input:    "DIR=${HOME}/app\nNAME=api\nBIN=\"/srv/${NAME}/bin\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(nil)},
+			expected: map[string]string{"DIR": "/app", "NAME": "api", "BIN": "/srv/api/bin"},
+		},
+		{
+			name:     "lookup",
+			input:    "DIR=${HOME}/app\nBIN=\"${DIR}/bin\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"DIR": "/home/gopher/app", "BIN": "/home/gopher/app/bin"},
+		},
+		{
+			name:     "variables of the file take precedence over the lookup",
+			input:    "HOME=/root\nDIR=${HOME}/app\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"HOME": "/root", "DIR": "/root/app"},
+		},
+		{
+			name:     "self-reference",
+			input:    "PATH=${PATH}:/opt/bin\nPATH=\"${PATH}:/usr/local/bin\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"PATH": "/usr/bin:/opt/bin:/usr/local/bin"},
+		},
+		{
+			name:     "default",
+			input:    "A=${MISSING:-fallback}\nB=${EMPTY:-fallback}\nC=${HOME:-fallback}\nD=\"${MISSING:-}\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"A": "fallback", "B": "fallback", "C": "/home/gopher", "D": ""},
+		},
+		{
+			name:     "escaped default",
+			input:    "A=\"${MISSING:-a\\nb}\"\nB=${MISSING:-a\\tb}\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"A": "a\nb", "B": "a\tb"},
+		},
+		{
+			name:     "single quotes and heredocs are literal",
+			input:    "A='${HOME}'\nB<<EOF\n${HOME}\nEOF\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"A": "${HOME}", "B": "${HOME}"},
+		},
+		{
+			name:     "commands are kept literal",
+			input:    "A=\"${HOME}/$(whoami)\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"A": "/home/gopher/$(whoami)"},
+		},
+		{
+			name:  "commands and references",
+			input: "A=\"${HOME}/$(whoami)\"\n",
+			opts: []godenv.Option{
+				godenv.WithLookup(lookup),
+				godenv.WithCommandRunner(godenv.CommandRunnerFunc(func(command string) (string, error) {
+					return "gopher\n", nil
+				})),
+			},
+			expected: map[string]string{"A": "/home/gopher/gopher"},
+		},
+		{
+			name:     "not a reference",
+			input:    "A=\"$HOME ${} ${HOME\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup)},
+			expected: map[string]string{"A": "$HOME ${} ${HOME"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := godenv.Parse(bytes.NewBufferString(tt.input), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestParse_ExpandInDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	lookup := godenv.MapSource{"HOME": "/home/gopher", "PATH": "/usr/bin"}.Lookup
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []godenv.Option
+		expected map[string]string
+	}{
+		{
+			name:     "shell order",
+			input:    "URL=http://${HOST}:${PORT}\nHOST=localhost\nPORT=8080\n",
+			opts:     []godenv.Option{godenv.WithLookup(nil)},
+			expected: map[string]string{"URL": "http://:", "HOST": "localhost", "PORT": "8080"},
+		},
+		{
+			name:     "forward references",
+			input:    "URL=http://${HOST}:${PORT}\nHOST=localhost\nPORT=8080\n",
+			opts:     []godenv.Option{godenv.ExpandInDependencyOrder()},
+			expected: map[string]string{"URL": "http://localhost:8080", "HOST": "localhost", "PORT": "8080"},
+		},
+		{
+			name:     "chain",
+			input:    "C=\"${B}/c\"\nB=\"${A}/b\"\nA=${HOME}\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup), godenv.ExpandInDependencyOrder()},
+			expected: map[string]string{"A": "/home/gopher", "B": "/home/gopher/b", "C": "/home/gopher/b/c"},
+		},
+		{
+			name:     "the last assignment",
+			input:    "HOST=localhost\nURL=http://${HOST}\nHOST=example.com\n",
+			opts:     []godenv.Option{godenv.ExpandInDependencyOrder()},
+			expected: map[string]string{"HOST": "example.com", "URL": "http://example.com"},
+		},
+		{
+			name:     "self-reference",
+			input:    "BIN=/opt/bin\nPATH=${PATH}:${BIN}\nPATH=\"${PATH}:/usr/local/bin\"\n",
+			opts:     []godenv.Option{godenv.WithLookup(lookup), godenv.ExpandInDependencyOrder()},
+			expected: map[string]string{"BIN": "/opt/bin", "PATH": "/usr/bin:/opt/bin:/usr/local/bin"},
+		},
+		{
+			name:     "heredoc",
+			input:    "GREETING=\"${TEXT}!\"\nTEXT<<EOF\nhello\nEOF\n",
+			opts:     []godenv.Option{godenv.ExpandInDependencyOrder()},
+			expected: map[string]string{"GREETING": "hello!", "TEXT": "hello"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := godenv.Parse(bytes.NewBufferString(tt.input), tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestParseFS_ExpandInDependencyOrderWithIncludes(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		".env":       {Data: []byte("URL=http://${HOST}:${PORT}\nPORT=${DEFAULT_PORT}\n#include common.env\nNAME=${APP}\n")},
+		"common.env": {Data: []byte("HOST=${DOMAIN:-localhost}\nDEFAULT_PORT=8080\nPORT=80\nAPP=${NAME:-api}\n")},
+	}
+
+	values, err := godenv.ParseFS(fsys, ".env", godenv.ExpandInDependencyOrder())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"URL":          "http://localhost:8080",
+		"HOST":         "localhost",
+		"DEFAULT_PORT": "8080",
+		"PORT":         "80",  // the included value overrides the assignment before the directive
+		"APP":          "api", // the included files do not see the variables of the including file
+		"NAME":         "api",
+	}, values)
+}
+
+func TestReadFile_ReferenceCycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "two variables",
+			input:    "A=${B}\nB=${A}\n",
+			expected: ".env:1:1: reference cycle: A (1:1) -> B (2:1) -> A",
+		},
+		{
+			name:     "reference with a default",
+			input:    "A=x\nB=\"${C}/b\"\nC=${B:-c}\n",
+			expected: ".env:2:1: reference cycle: B (2:1) -> C (3:1) -> B",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			name := filepath.Join(t.TempDir(), ".env")
+			require.NoError(t, os.WriteFile(name, []byte(tt.input), 0o600))
+
+			vars, err := godenv.ReadFile(name, godenv.ExpandInDependencyOrder())
+			require.ErrorIs(t, err, godenv.ErrReferenceCycle)
+			assert.Equal(t, filepath.Dir(name)+string(filepath.Separator)+tt.expected, err.Error())
+			assert.Nil(t, vars)
+
+			var envErr *godenv.Error
+			require.ErrorAs(t, err, &envErr)
+			assert.Equal(t, name, envErr.Pos.Filename)
+		})
+	}
+}
+
+func TestParseFile_Include(t *testing.T) {
+	writeFile := func(t *testing.T, name, content string) {
+		t.Helper()
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+	}
+
+	t.Run("included variables are overridden by the following assignments", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".env"), "FOO=before\nBAR=before\n#include config/common.env\nBAR=after\n")
+		writeFile(t, filepath.Join(dir, "config", "common.env"), "FOO=common\nBAR=common\n#include \"local.env\"\n")
+		writeFile(t, filepath.Join(dir, "config", "local.env"), "BAZ=local\n")
+
+		values, err := godenv.ParseFile(filepath.Join(dir, ".env"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"FOO": "common",
+			"BAR": "after",
+			"BAZ": "local",
+		}, values)
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "a.env"), "#include b.env\n")
+		writeFile(t, filepath.Join(dir, "b.env"), "FOO=bar\n#include ./a.env\n")
+
+		values, err := godenv.ParseFile(filepath.Join(dir, "a.env"))
+		require.ErrorIs(t, err, godenv.ErrIncludeCycle)
+		assert.Nil(t, values)
+
+		var parseErr *godenv.Error
+		require.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, godenv.Position{Filename: filepath.Join(dir, "b.env"), Line: 2, Column: 1}, parseErr.Pos)
+		assert.Equal(t, []godenv.Position{{Filename: filepath.Join(dir, "a.env"), Line: 1, Column: 1}}, parseErr.IncludedFrom)
+	})
+
+	t.Run("include depth limit", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "1.env"), "#include 2.env\n")
+		writeFile(t, filepath.Join(dir, "2.env"), "#include 3.env\n")
+		writeFile(t, filepath.Join(dir, "3.env"), "FOO=bar\n")
+
+		_, err := godenv.ParseFile(filepath.Join(dir, "1.env"), godenv.WithMaxIncludeDepth(1))
+		require.ErrorIs(t, err, godenv.ErrIncludeDepth)
+
+		values, err := godenv.ParseFile(filepath.Join(dir, "1.env"), godenv.WithMaxIncludeDepth(2))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"FOO": "bar"}, values)
+	})
+
+	t.Run("syntax error in the included file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".env"), "FOO=bar\n#include common.env\n")
+		writeFile(t, filepath.Join(dir, "common.env"), "BAR=baz\nBAZ= qux\n")
+
+		_, err := godenv.ParseFile(filepath.Join(dir, ".env"))
+		require.Error(t, err)
+		assert.Equal(t,
+			filepath.Join(dir, "common.env")+`:2:5: unexpected token: SPACE( ) (included from `+
+				filepath.Join(dir, ".env")+":2:1)",
+			err.Error(),
+		)
+	})
+
+	// The comments that start with "#include " were ignored before the include directives were supported,
+	// now they are the directives. A space after "#" keeps such a line a comment.
+	t.Run("comment that starts with the directive", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "directive.env"), "#include missing.env\nFOO=bar\n")
+		writeFile(t, filepath.Join(dir, "comment.env"), "# include missing.env\n#includes come first\nFOO=bar\n")
+
+		_, err := godenv.ParseFile(filepath.Join(dir, "directive.env"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+
+		values, err := godenv.ParseFile(filepath.Join(dir, "comment.env"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"FOO": "bar"}, values)
+	})
+}
+
+func TestParse_Include(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret.env")
+	require.NoError(t, os.WriteFile(secret, []byte("X=topsecret\n"), 0o600))
+
+	t.Run("rejected by default", func(t *testing.T) {
+		t.Parallel()
+
+		for _, input := range []string{"#include " + secret + "\n", "FOO=bar\n#include secret.env\n"} {
+			values, err := godenv.Parse(bytes.NewBufferString(input))
+			require.ErrorIs(t, err, godenv.ErrIncludeNotAllowed, input)
+			assert.Nil(t, values)
+			assert.NotContains(t, err.Error(), "topsecret")
+		}
+	})
+
+	t.Run("resolved against the named file", func(t *testing.T) {
+		t.Parallel()
+
+		values, err := godenv.Parse(bytes.NewBufferString("#include secret.env\n"), godenv.WithFilename(filepath.Join(dir, ".env")))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"X": "topsecret"}, values)
+	})
+}
+
+func TestParse_WithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".env":            {Data: []byte("#include config/app.env\nFOO=root\n")},
+		"config/app.env":  {Data: []byte("FOO=app\n#include /shared.env\n#include db.env\n")},
+		"config/db.env":   {Data: []byte("DB=postgres\n")},
+		"shared.env":      {Data: []byte("SHARED=true\n")},
+		"escape/bad.env":  {Data: []byte("#include ../../secrets.env\n")},
+		"missing/bad.env": {Data: []byte("#include nope.env\n")},
+	}
+
+	t.Run("includes are resolved inside the file system", func(t *testing.T) {
+		values, err := godenv.ParseFile(".env", godenv.WithFS(fsys))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"FOO":    "root",
+			"SHARED": "true",
+			"DB":     "postgres",
+		}, values)
+	})
+
+	t.Run("ParseFS", func(t *testing.T) {
+		values, err := godenv.ParseFS(fsys, "config/app.env", godenv.WithFS(fstest.MapFS{}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"FOO":    "app",
+			"SHARED": "true",
+			"DB":     "postgres",
+		}, values)
+	})
+
+	t.Run("reader includes are resolved against the root", func(t *testing.T) {
+		values, err := godenv.Parse(bytes.NewBufferString("#include config/db.env"), godenv.WithFS(fsys))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"DB": "postgres"}, values)
+	})
+
+	t.Run("include outside of the file system", func(t *testing.T) {
+		_, err := godenv.ParseFile("escape/bad.env", godenv.WithFS(fsys))
+		require.EqualError(t, err, `escape/bad.env:1:1: include path "../../secrets.env" is outside of the file system`)
+	})
+
+	t.Run("missing include", func(t *testing.T) {
+		_, err := godenv.ParseFile("missing/bad.env", godenv.WithFS(fsys))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
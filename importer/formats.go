@@ -0,0 +1,352 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readJSON reads a JSON object. The numbers are kept as they are written, null is an empty value.
+func readJSON(src []byte, add func(name, value string) error, o *options) error {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the top-level object")
+	}
+
+	if _, ok := value.(map[string]interface{}); !ok {
+		return errors.New("the top-level value must be an object")
+	}
+
+	return flattenJSON("", value, add, o)
+}
+
+func flattenJSON(name string, value interface{}, add func(name, value string) error, o *options) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			if err := flattenJSON(join(name, key, o), elem, add, o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case []interface{}:
+		for i, elem := range v {
+			if err := flattenJSON(join(name, strconv.Itoa(i), o), elem, add, o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case nil:
+		return add(name, "")
+	case string:
+		return add(name, v)
+	default:
+		return add(name, fmt.Sprint(v))
+	}
+}
+
+// readYAML reads a YAML mapping. The scalars are kept as they are written, null is an empty value.
+func readYAML(src []byte, add func(name, value string) error, o *options) error {
+	var doc yaml.Node
+	if err := unmarshalYAML(src, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil // an empty document
+	}
+
+	if root := resolveAlias(doc.Content[0]); root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: the top-level value must be a mapping", root.Line)
+	}
+
+	return flattenYAML("", doc.Content[0], add, o, make(map[*yaml.Node]bool))
+}
+
+// unmarshalYAML parses the YAML document, turning a panic of the parser on a malformed input into an error.
+func unmarshalYAML(src []byte, doc *yaml.Node) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("malformed document")
+		}
+	}()
+
+	return yaml.Unmarshal(src, doc)
+}
+
+// flattenYAML adds the scalars of the node. The nodes that are being flattened are kept in visiting,
+// so an alias that refers to a node that contains it is an error instead of an endless recursion.
+func flattenYAML(name string, node *yaml.Node, add func(name, value string) error, o *options, visiting map[*yaml.Node]bool) error {
+	alias := node
+	node = resolveAlias(node)
+
+	if visiting[node] {
+		return fmt.Errorf("line %d: the alias refers to a node that contains it", alias.Line)
+	}
+
+	visiting[node] = true
+	defer delete(visiting, node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := resolveAlias(node.Content[i])
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: the key must be a scalar", key.Line)
+			}
+
+			if err := flattenYAML(join(name, key.Value, o), node.Content[i+1], add, o, visiting); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case yaml.SequenceNode:
+		for i, elem := range node.Content {
+			if err := flattenYAML(join(name, strconv.Itoa(i), o), elem, add, o, visiting); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		if node.Tag == "!!null" {
+			return add(name, "")
+		}
+
+		return add(name, node.Value)
+	}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+func join(prefix, key string, o *options) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + o.separator + key
+}
+
+// readProperties reads a Java .properties file as java.util.Properties does: the keys are separated from
+// the values with "=", ":" or whitespace, a backslash at the end of the line continues it on the next line,
+// and the escape sequences, including \uXXXX, are replaced. A repeated key overrides the previous value.
+func readProperties(src []byte, add func(name, value string) error, _ *options) error {
+	if !utf8.Valid(src) {
+		return errors.New("illegal UTF-8 encoding")
+	}
+
+	var (
+		keys   []string
+		values = make(map[string]string)
+	)
+
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(string(src)), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value := splitProperty(line)
+
+		key, err := unescapeProperty(key)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if value, err = unescapeProperty(value); err != nil {
+			return fmt.Errorf("line %d: key %s: %w", i+1, key, err)
+		}
+
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		values[key] = value
+	}
+
+	for _, key := range keys {
+		if err := add(key, values[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// continued reports whether the line ends with an odd number of backslashes.
+func continued(line string) bool {
+	n := 0
+	for n < len(line) && line[len(line)-1-n] == '\\' {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// splitProperty splits the line into the escaped key and value.
+func splitProperty(line string) (string, string) {
+	end := 0
+	for ; end < len(line); end++ {
+		if line[end] == '\\' {
+			end++
+			continue
+		}
+
+		if strings.IndexByte("=: \t\f", line[end]) >= 0 {
+			break
+		}
+	}
+
+	if end > len(line) {
+		end = len(line)
+	}
+
+	key, rest := line[:end], strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	return key, rest
+}
+
+// errMalformedUnicode does not quote the escape sequence, since it is a part of a value that may be a secret.
+var errMalformedUnicode = errors.New("malformed \\uXXXX escape sequence")
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errMalformedUnicode
+			}
+
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errMalformedUnicode
+			}
+
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// readEnv reads the NAME=value lines. A line that does not start with a name followed by "=" continues the value
+// of the previous line, so a multi-line value is read intact unless one of its lines looks like an assignment;
+// use the output of env -0 to read such values.
+func readEnv(src []byte, add func(name, value string) error, _ *options) error {
+	var entries []string
+
+	for i, line := range strings.Split(strings.TrimSuffix(string(src), "\n"), "\n") {
+		if name, _, ok := cutEntry(line); ok && !strings.ContainsAny(name, " \t") {
+			entries = append(entries, line)
+			continue
+		}
+
+		if len(entries) == 0 {
+			if line == "" {
+				continue
+			}
+
+			return fmt.Errorf("line %d: malformed entry", i+1)
+		}
+
+		entries[len(entries)-1] += "\n" + line
+	}
+
+	return addEntries(entries, add)
+}
+
+// readEnvNull reads the NAME=value entries terminated with NUL.
+func readEnvNull(src []byte, add func(name, value string) error, _ *options) error {
+	entries := strings.Split(strings.TrimSuffix(string(src), "\x00"), "\x00")
+	if len(src) == 0 {
+		entries = nil
+	}
+
+	return addEntries(entries, add)
+}
+
+func addEntries(entries []string, add func(name, value string) error) error {
+	for i, entry := range entries {
+		name, value, ok := cutEntry(entry)
+		if !ok {
+			return fmt.Errorf("entry %d: malformed entry", i+1)
+		}
+
+		if err := add(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cutEntry splits the NAME=value entry, the name must not be empty.
+func cutEntry(entry string) (string, string, bool) {
+	i := strings.IndexByte(entry, '=')
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return entry[:i], entry[i+1:], true
+}
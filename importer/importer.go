@@ -0,0 +1,154 @@
+// Package importer converts the variables from the formats of other tools into the .env format: JSON and YAML
+// documents, Java .properties files, and the output of the env command.
+//
+// The nested keys of JSON and YAML are flattened by joining them with a separator, "__" by default,
+// so {"DB": {"HOST": "localhost"}} becomes DB__HOST=localhost, and the elements of the arrays are numbered
+// from 0. The names that are not valid .env identifiers are rejected, unless Sanitize is given.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+// The names of the formats.
+const (
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatProperties = "properties"
+	FormatEnv        = "env"  // the output of env: NAME=value lines
+	FormatEnvNull    = "env0" // the output of env -0: NAME=value entries terminated with NUL
+)
+
+const defaultSeparator = "__"
+
+// Format is an input format.
+type Format struct {
+	Name        string
+	Description string
+	read        func(src []byte, add func(name, value string) error, o *options) error
+}
+
+// nolint:gochecknoglobals
+var formats = []Format{
+	{Name: FormatJSON, Description: "JSON object", read: readJSON},
+	{Name: FormatYAML, Description: "YAML mapping", read: readYAML},
+	{Name: FormatProperties, Description: "Java .properties file", read: readProperties},
+	{Name: FormatEnv, Description: "output of env", read: readEnv},
+	{Name: FormatEnvNull, Description: "output of env -0", read: readEnvNull},
+}
+
+// Formats returns all the formats.
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// LookupFormat returns the format by its name.
+func LookupFormat(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// Option configures the conversion.
+type Option func(*options)
+
+type options struct {
+	separator string
+	sanitize  bool
+}
+
+// Separator sets the separator of the nested keys, "__" by default.
+func Separator(separator string) Option {
+	return func(o *options) {
+		o.separator = separator
+	}
+}
+
+// Sanitize replaces the characters that are not allowed in the names with "_", instead of rejecting the names.
+func Sanitize() Option {
+	return func(o *options) {
+		o.sanitize = true
+	}
+}
+
+// Read reads the variables in the format.
+// Read returns an error if the input is malformed, if a name is not a valid .env identifier,
+// or if two keys are converted into the same name.
+func Read(r io.Reader, format string, opts ...Option) (map[string]string, error) {
+	f, ok := LookupFormat(format)
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	o := &options{separator: defaultSeparator}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+
+	add := func(name, value string) error {
+		if !scanner.IsIdentifier(name) {
+			if !o.sanitize {
+				return fmt.Errorf("invalid variable name: %q", name)
+			}
+
+			sanitized := sanitize(name)
+			if sanitized == "" {
+				return fmt.Errorf("invalid variable name: %q", name)
+			}
+
+			name = sanitized
+		}
+
+		if _, ok := vars[name]; ok {
+			return fmt.Errorf("duplicate variable name: %q", name)
+		}
+
+		vars[name] = value
+
+		return nil
+	}
+
+	if err := f.read(src, add, o); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+
+	return vars, nil
+}
+
+// Convert reads the variables in the format from r and writes them to w in the canonical .env format,
+// sorted by name. See Read for details.
+func Convert(w io.Writer, r io.Reader, format string, opts ...Option) error {
+	vars, err := Read(r, format, opts...)
+	if err != nil {
+		return err
+	}
+
+	return godenv.Write(w, vars)
+}
+
+// sanitize replaces the characters that are not allowed in the names with "_".
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if scanner.IsIdentifier(string(r)) {
+			return r
+		}
+
+		return '_'
+	}, name)
+}
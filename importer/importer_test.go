@@ -0,0 +1,278 @@
+package importer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/export"
+	"github.com/youla-dev/godenv/importer"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		opts     []importer.Option
+		expected map[string]string
+	}{
+		{
+			name:   "json",
+			format: importer.FormatJSON,
+			input: `{
+				"APP": "api",
+				"DB": {"HOST": "localhost", "PORT": 5432, "RATIO": 1.50, "SSL": false, "PASSWORD": null},
+				"HOSTS": ["a", "b"],
+				"EMPTY": {}
+			}`,
+			expected: map[string]string{
+				"APP":          "api",
+				"DB__HOST":     "localhost",
+				"DB__PORT":     "5432",
+				"DB__RATIO":    "1.50",
+				"DB__SSL":      "false",
+				"DB__PASSWORD": "",
+				"HOSTS__0":     "a",
+				"HOSTS__1":     "b",
+			},
+		},
+		{
+			name:     "json with separator",
+			format:   importer.FormatJSON,
+			input:    `{"DB": {"HOST": "localhost"}}`,
+			opts:     []importer.Option{importer.Separator("_")},
+			expected: map[string]string{"DB_HOST": "localhost"},
+		},
+		{
+			name:   "yaml",
+			format: importer.FormatYAML,
+			input: `# settings
+APP: api
+DB: &db
+  HOST: localhost
+  PORT: 05432
+  SSL: off
+  PASSWORD: ~
+REPLICA: *db
+HOSTS:
+  - a
+  - b
+MOTD: |
+  Hello,
+  world
+`,
+			expected: map[string]string{
+				"APP":               "api",
+				"DB__HOST":          "localhost",
+				"DB__PORT":          "05432",
+				"DB__SSL":           "off",
+				"DB__PASSWORD":      "",
+				"REPLICA__HOST":     "localhost",
+				"REPLICA__PORT":     "05432",
+				"REPLICA__SSL":      "off",
+				"REPLICA__PASSWORD": "",
+				"HOSTS__0":          "a",
+				"HOSTS__1":          "b",
+				"MOTD":              "Hello,\nworld\n",
+			},
+		},
+		{
+			name:     "empty yaml",
+			format:   importer.FormatYAML,
+			input:    "# nothing\n",
+			expected: map[string]string{},
+		},
+		{
+			name:   "properties",
+			format: importer.FormatProperties,
+			input: "# comment\n! comment\n" +
+				"db.host = localhost\n" +
+				"db.port:5432\n" +
+				"db.user   admin\n" +
+				"message=Hello, \\\n    world\n" +
+				"path=C:\\\\temp\\tdir\n" +
+				"unicode=caf\\u00e9\n" +
+				"key\\ with\\ spaces=1\n" +
+				"empty\n" +
+				"db.host=override\r\n",
+			opts: []importer.Option{importer.Sanitize()},
+			expected: map[string]string{
+				"db.host":         "override",
+				"db.port":         "5432",
+				"db.user":         "admin",
+				"message":         "Hello, world",
+				"path":            "C:\\temp\tdir",
+				"unicode":         "café",
+				"key_with_spaces": "1",
+				"empty":           "",
+			},
+		},
+		{
+			name:   "env",
+			format: importer.FormatEnv,
+			input:  "HOME=/root\nMOTD=Hello,\n\nworld\nEMPTY=\nEQUALS=a=b\n",
+			expected: map[string]string{
+				"HOME":   "/root",
+				"MOTD":   "Hello,\n\nworld",
+				"EMPTY":  "",
+				"EQUALS": "a=b",
+			},
+		},
+		{
+			name:   "env -0",
+			format: importer.FormatEnvNull,
+			input:  "HOME=/root\x00MOTD=line\nFOO=bar\x00EMPTY=\x00",
+			expected: map[string]string{
+				"HOME":  "/root",
+				"MOTD":  "line\nFOO=bar",
+				"EMPTY": "",
+			},
+		},
+		{
+			name:     "sanitized names",
+			format:   importer.FormatEnvNull,
+			input:    "BASH_FUNC_f%%=() {  echo\n}\x00",
+			opts:     []importer.Option{importer.Sanitize()},
+			expected: map[string]string{"BASH_FUNC_f__": "() {  echo\n}"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			vars, err := importer.Read(strings.NewReader(tc.input), tc.format, tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, vars)
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		opts     []importer.Option
+		expected string
+	}{
+		{name: "unknown format", format: "toml", expected: `unknown format "toml"`},
+		{name: "json array", format: importer.FormatJSON, input: `["a"]`, expected: "json: the top-level value must be an object"},
+		{name: "json trailing data", format: importer.FormatJSON, input: `{} {}`, expected: "json: unexpected data after the top-level object"},
+		{name: "invalid name", format: importer.FormatJSON, input: `{"my var": "1"}`, expected: `json: invalid variable name: "my var"`},
+		{
+			name:     "duplicate name",
+			format:   importer.FormatJSON,
+			input:    `{"A": {"B": "1"}, "A__B": "2"}`,
+			expected: `json: duplicate variable name: "A__B"`,
+		},
+		{name: "yaml scalar", format: importer.FormatYAML, input: "text\n", expected: "yaml: line 1: the top-level value must be a mapping"},
+		{name: "yaml complex key", format: importer.FormatYAML, input: "? [a]\n: b\n", expected: "yaml: line 1: the key must be a scalar"},
+		{
+			name:     "yaml recursive alias",
+			format:   importer.FormatYAML,
+			input:    "a: &a [*a]\n",
+			expected: "yaml: line 1: the alias refers to a node that contains it",
+		},
+		{
+			name:     "yaml recursive alias in a mapping",
+			format:   importer.FormatYAML,
+			input:    "a: &a\n  b: *a\n",
+			expected: "yaml: line 2: the alias refers to a node that contains it",
+		},
+		{
+			name:     "malformed unicode escape",
+			format:   importer.FormatProperties,
+			input:    "a=1\nb=\\u00zz\n",
+			expected: `properties: line 2: key b: malformed \uXXXX escape sequence`,
+		},
+		{name: "malformed env", format: importer.FormatEnv, input: "\nno assignment\n", expected: "env: line 2: malformed entry"},
+		{name: "malformed env -0", format: importer.FormatEnvNull, input: "A=1\x00=C:=C:\\\x00", expected: "env0: entry 2: malformed entry"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := importer.Read(strings.NewReader(tc.input), tc.format, tc.opts...)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRead_ErrorOmitsValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{name: "properties", format: importer.FormatProperties, input: "password=s3cr3t\\u12\n"},
+		{name: "env", format: importer.FormatEnv, input: "s3cr3t\nPASSWORD=1\n"},
+		{name: "env -0", format: importer.FormatEnvNull, input: "s3cr3t\x00"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := importer.Read(strings.NewReader(tc.input), tc.format)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "s3cr3t")
+		})
+	}
+}
+
+func TestRead_MalformedYAML(t *testing.T) {
+	t.Parallel()
+
+	// The input made the earlier versions of the YAML parser panic (CVE-2022-28948).
+	_, err := importer.Read(strings.NewReader("0: [:!00 \xef"), importer.FormatYAML)
+	assert.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := importer.Convert(&buf, strings.NewReader(`{"DB": {"PORT": 5432, "HOST": "db host"}, "MOTD": "it's\n\"quoted\""}`), importer.FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, "DB__HOST='db host'\nDB__PORT=5432\nMOTD<<EOF\nit's\n\"quoted\"\nEOF\n", buf.String())
+
+	vars, err := godenv.Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB__HOST": "db host", "DB__PORT": "5432", "MOTD": "it's\n\"quoted\""}, vars)
+}
+
+func TestRead_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]string{"PLAIN": "value", "EMPTY": "", "QUOTES": `it's "quoted"`, "MULTILINE": "a\nb\n", "YES": "yes"}
+
+	for _, format := range []string{export.FormatJSON, export.FormatYAML} {
+		var buf bytes.Buffer
+
+		require.NoError(t, export.Write(&buf, format, godenv.NewVars(values)))
+
+		vars, err := importer.Read(&buf, format)
+		require.NoError(t, err, format)
+		assert.Equal(t, values, vars, format)
+	}
+}
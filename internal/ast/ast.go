@@ -1,6 +1,10 @@
 // Package ast declares the types used to represent syntax trees for the .env file.
 package ast
 
+import (
+	"github.com/youla-dev/godenv/internal/token"
+)
+
 // Node represents AST-node of the syntax tree.
 type Node interface{}
 
@@ -16,16 +20,77 @@ type FileStatement struct {
 }
 
 // AssignStatement node represents a assignment statement.
+//
+// If the value contains substitutions or variable references, Parts holds the literal text, the substitutions and
+// the references in the order of their appearance, and Value holds the value as it is written,
+// e.g. "sha-$(git rev-parse HEAD)" or "http://${HOST}".
 type AssignStatement struct {
-	Name  string
-	Value string
+	Name   string
+	Value  string
+	Parts  []ValuePart
+	Quoted bool // the value is written in single or double quotes
+	Pos    token.Position
+}
+
+// HeredocStatement node represents an assignment of a multi-line value:
+// the NAME<<DELIMITER line, the lines of the value and the closing DELIMITER line.
+type HeredocStatement struct {
+	Name      string
+	Delimiter string
+	Indented  bool // the NAME<<~DELIMITER form: the common indentation of the lines is not a part of the value
+	Value     string
+	Pos       token.Position
 }
 
 // CommentStatement node represents a comment statement.
 type CommentStatement struct {
 	Value string
+	Pos   token.Position
+}
+
+// BlankLineStatement node represents an empty line, or a line that consists of whitespace characters only.
+type BlankLineStatement struct {
+	Pos token.Position
+}
+
+// IncludeStatement node represents an include directive: #include <path>.
+type IncludeStatement struct {
+	Path string
+	Pos  token.Position
 }
 
-func (s *FileStatement) statementNode()    {}
-func (s *AssignStatement) statementNode()  {}
-func (s *CommentStatement) statementNode() {}
+// ValuePart represents syntax tree node of a part of the value: a literal text or a substitution.
+type ValuePart interface {
+	Node
+	valuePart()
+}
+
+// Text node represents a literal text of the value.
+type Text struct {
+	Value string
+}
+
+// CommandSubstitution node represents a $(command) substitution.
+type CommandSubstitution struct {
+	Command string
+}
+
+// VariableReference node represents a reference to a variable: ${NAME}, or ${NAME:-default}
+// that falls back to the default if the variable is not set or is empty.
+type VariableReference struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	Pos        token.Position // the position of the $ sign
+}
+
+func (s *FileStatement) statementNode()      {}
+func (s *AssignStatement) statementNode()    {}
+func (s *HeredocStatement) statementNode()   {}
+func (s *CommentStatement) statementNode()   {}
+func (s *IncludeStatement) statementNode()   {}
+func (s *BlankLineStatement) statementNode() {}
+
+func (p *Text) valuePart()                {}
+func (p *CommandSubstitution) valuePart() {}
+func (p *VariableReference) valuePart()   {}
@@ -0,0 +1,167 @@
+// Package diff implements the line-oriented comparison of texts in the unified format.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines printed around the changes.
+const context = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	a, b int // 0-based line numbers in the old and in the new text before the operation
+}
+
+// Unified returns the difference between the old and the new text in the unified format,
+// or an empty string if the texts are equal.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := compare(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, h := range hunks(ops) {
+		writeHunk(&b, ops[h[0]:h[1]])
+	}
+
+	return b.String()
+}
+
+// splitLines splits the text into lines that keep their line breaks.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// compare returns the shortest edit script that turns a into b, based on the longest common subsequence.
+func compare(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], a: i, b: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], a: i, b: j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// hunks returns the ranges of the operations that are printed as hunks: the changes with the context around them.
+// The hunks that overlap or touch each other are merged.
+func hunks(ops []op) [][2]int {
+	var ranges [][2]int
+
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+
+		start, end := i-context, i+1+context
+		if start < 0 {
+			start = 0
+		}
+
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		if n := len(ranges); n > 0 && ranges[n-1][1] >= start {
+			ranges[n-1][1] = end
+		} else {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+
+	return ranges
+}
+
+func writeHunk(b *strings.Builder, ops []op) {
+	var oldLines, newLines int
+
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldLines++
+		}
+
+		if o.kind != opDelete {
+			newLines++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(ops[0].a, oldLines), hunkRange(ops[0].b, newLines))
+
+	for _, o := range ops {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.line)
+
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange returns the range of the hunk in the "start,count" form, the start is 1-based.
+// An empty range starts at the line before the hunk.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
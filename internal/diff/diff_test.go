@@ -0,0 +1,90 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/youla-dev/godenv/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name: "equal",
+			old:  "A=1\n",
+			new:  "A=1\n",
+		},
+		{
+			name: "changed line",
+			old:  "A=1\nB=2\nC=3\n",
+			new:  "A=1\nB='2 3'\nC=3\n",
+			expected: `--- a
++++ b
+@@ -1,3 +1,3 @@
+ A=1
+-B=2
++B='2 3'
+ C=3
+`,
+		},
+		{
+			name: "insertion into empty text",
+			old:  "",
+			new:  "A=1\n",
+			expected: `--- a
++++ b
+@@ -0,0 +1 @@
++A=1
+`,
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "0\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			expected: `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++0
+ 2
+ 3
+ 4
+@@ -7,4 +7,3 @@
+ 7
+ 8
+ 9
+-10
+`,
+		},
+		{
+			name: "missing line break",
+			old:  "A=1",
+			new:  "A=1\n",
+			expected: `--- a
++++ b
+@@ -1 +1 @@
+-A=1
+\ No newline at end of file
++A=1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, diff.Unified("a", "b", tt.old, tt.new))
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Package edit implements editing of the .env files that changes the edited assignments only:
+// the comments, the blank lines, the notation of the other values and the order of the lines are kept intact.
+package edit
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+// Assignment is an assignment of a variable in the file.
+type Assignment struct {
+	Name     string
+	Value    string // the value as it is written, without quotes; the substitutions are not evaluated
+	Commands bool   // the value contains $(command) substitutions
+	Line     int    // the 1-based line of the assignment
+
+	start, end  int      // the lines of the statement: [start, end)
+	replacement *string  // the new text of the statement, an empty string deletes it
+	inserted    []string // the statements inserted after the statement
+}
+
+// File is an .env file being edited.
+type File struct {
+	lines       []string // the lines of the source, with the line breaks
+	assignments []*Assignment
+	appended    []string
+}
+
+// Parse parses the source for editing. If the source cannot be parsed, Parse returns *parser.Error.
+func Parse(src []byte) (*File, error) {
+	statement, err := parser.New(scanner.New(string(src))).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	file, ok := statement.(*ast.FileStatement)
+	if !ok {
+		return nil, errors.New("unexpected statement")
+	}
+
+	f := &File{lines: strings.SplitAfter(string(src), "\n")}
+	if f.lines[len(f.lines)-1] == "" {
+		f.lines = f.lines[:len(f.lines)-1]
+	}
+
+	// Every statement starts on its own line, and ends before the next one starts.
+	for i, stmt := range file.Statements {
+		var a *Assignment
+
+		switch s := stmt.(type) {
+		case *ast.AssignStatement:
+			a = &Assignment{Name: s.Name, Value: s.Value, Line: s.Pos.Line}
+
+			for _, part := range s.Parts {
+				if _, ok := part.(*ast.CommandSubstitution); ok {
+					a.Commands = true
+				}
+			}
+		case *ast.HeredocStatement:
+			a = &Assignment{Name: s.Name, Value: s.Value, Line: s.Pos.Line}
+		default:
+			continue
+		}
+
+		a.start, a.end = a.Line-1, len(f.lines)
+		if i+1 < len(file.Statements) {
+			a.end = line(file.Statements[i+1]) - 1
+		}
+
+		f.assignments = append(f.assignments, a)
+	}
+
+	return f, nil
+}
+
+// line returns the line of the statement.
+func line(stmt ast.Statement) int {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		return s.Pos.Line
+	case *ast.HeredocStatement:
+		return s.Pos.Line
+	case *ast.CommentStatement:
+		return s.Pos.Line
+	case *ast.IncludeStatement:
+		return s.Pos.Line
+	case *ast.BlankLineStatement:
+		return s.Pos.Line
+	default:
+		return 0
+	}
+}
+
+// Assignments returns the assignments in the order of the lines, including the repeated assignments
+// of the same variable.
+func (f *File) Assignments() []*Assignment {
+	return append([]*Assignment(nil), f.assignments...)
+}
+
+// Lookup returns the assignments of the variable.
+func (f *File) Lookup(name string) []*Assignment {
+	var assignments []*Assignment
+
+	for _, a := range f.assignments {
+		if a.Name == name {
+			assignments = append(assignments, a)
+		}
+	}
+
+	return assignments
+}
+
+// Set replaces the assignment with the assignment of the value, written in the most readable notation.
+func (f *File) Set(a *Assignment, value string) {
+	text := statement(a.Name, value)
+	a.replacement = &text
+}
+
+// Delete removes the assignment.
+func (f *File) Delete(a *Assignment) {
+	text := ""
+	a.replacement = &text
+}
+
+// InsertAfter adds the assignment of the value on the line that follows the assignment a.
+func (f *File) InsertAfter(a *Assignment, name, value string) {
+	a.inserted = append(a.inserted, statement(name, value))
+}
+
+// Append adds the assignment of the value to the end of the file.
+func (f *File) Append(name, value string) {
+	f.appended = append(f.appended, statement(name, value))
+}
+
+// Bytes returns the edited source.
+func (f *File) Bytes() []byte {
+	var buf bytes.Buffer
+
+	next := 0
+
+	for _, a := range f.assignments {
+		if a.replacement == nil && len(a.inserted) == 0 {
+			continue
+		}
+
+		for ; next < a.start; next++ {
+			buf.WriteString(f.lines[next])
+		}
+
+		if a.replacement != nil {
+			replacement := *a.replacement
+			if replacement != "" && a.end == len(f.lines) && !strings.HasSuffix(f.lines[a.end-1], "\n") {
+				replacement = strings.TrimSuffix(replacement, "\n") // keep the missing line break at the end
+			}
+
+			buf.WriteString(replacement)
+		} else {
+			for ; next < a.end; next++ {
+				buf.WriteString(f.lines[next])
+			}
+		}
+
+		next = a.end
+
+		writeStatements(&buf, a.inserted)
+	}
+
+	for ; next < len(f.lines); next++ {
+		buf.WriteString(f.lines[next])
+	}
+
+	writeStatements(&buf, f.appended)
+
+	return buf.Bytes()
+}
+
+// writeStatements writes the statements on the new lines.
+func writeStatements(buf *bytes.Buffer, statements []string) {
+	if len(statements) > 0 && buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	for _, text := range statements {
+		buf.WriteString(text)
+	}
+}
+
+// statement returns the text of the assignment with the line break.
+func statement(name, value string) string {
+	var buf bytes.Buffer
+
+	_ = printer.Fprint(&buf, printer.Assign(name, value)) // the callers check that the value can be written
+	buf.WriteByte('\n')
+
+	return buf.String()
+}
@@ -0,0 +1,146 @@
+package edit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv/internal/edit"
+)
+
+const src = `# Database
+DB_HOST="localhost"
+DB_PASSWORD='hunter2'
+
+CERT<<EOF
+line 1
+line 2
+EOF
+  # indented comment
+GIT_SHA=$(git rev-parse HEAD)
+DB_HOST=db
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	f, err := edit.Parse([]byte(src))
+	require.NoError(t, err)
+
+	var got []edit.Assignment
+	for _, a := range f.Assignments() {
+		got = append(got, edit.Assignment{Name: a.Name, Value: a.Value, Commands: a.Commands, Line: a.Line})
+	}
+
+	assert.Equal(t, []edit.Assignment{
+		{Name: "DB_HOST", Value: "localhost", Line: 2},
+		{Name: "DB_PASSWORD", Value: "hunter2", Line: 3},
+		{Name: "CERT", Value: "line 1\nline 2", Line: 5},
+		{Name: "GIT_SHA", Value: "$(git rev-parse HEAD)", Commands: true, Line: 10},
+		{Name: "DB_HOST", Value: "db", Line: 11},
+	}, got)
+
+	assert.Len(t, f.Lookup("DB_HOST"), 2)
+	assert.Empty(t, f.Lookup("UNKNOWN"))
+	assert.Equal(t, src, string(f.Bytes()), "the file is intact without edits")
+}
+
+func TestFile_Edit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		src      string
+		edit     func(f *edit.File)
+		expected string
+	}{
+		{
+			name: "set",
+			src:  src,
+			edit: func(f *edit.File) {
+				f.Set(f.Lookup("DB_PASSWORD")[0], "it's secret")
+			},
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD=\"it's secret\"\n\nCERT<<EOF\nline 1\nline 2\nEOF\n" +
+				"  # indented comment\nGIT_SHA=$(git rev-parse HEAD)\nDB_HOST=db\n",
+		},
+		{
+			name: "set heredoc",
+			src:  src,
+			edit: func(f *edit.File) {
+				f.Set(f.Lookup("CERT")[0], "single")
+			},
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\n\nCERT=single\n" +
+				"  # indented comment\nGIT_SHA=$(git rev-parse HEAD)\nDB_HOST=db\n",
+		},
+		{
+			name: "delete",
+			src:  src,
+			edit: func(f *edit.File) {
+				for _, a := range f.Lookup("DB_HOST") {
+					f.Delete(a)
+				}
+			},
+			expected: "# Database\nDB_PASSWORD='hunter2'\n\nCERT<<EOF\nline 1\nline 2\nEOF\n" +
+				"  # indented comment\nGIT_SHA=$(git rev-parse HEAD)\n",
+		},
+		{
+			name: "append",
+			src:  "A=1",
+			edit: func(f *edit.File) {
+				f.Append("B", "two words")
+				f.Append("C", "it's\n\"quoted\"")
+			},
+			expected: "A=1\nB='two words'\nC<<EOF\nit's\n\"quoted\"\nEOF\n",
+		},
+		{
+			name: "insert after",
+			src:  src,
+			edit: func(f *edit.File) {
+				f.InsertAfter(f.Lookup("CERT")[0], "KEY", "value")
+				f.InsertAfter(f.Lookup("DB_HOST")[1], "LAST", "1")
+			},
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\n\nCERT<<EOF\nline 1\nline 2\nEOF\nKEY=value\n" +
+				"  # indented comment\nGIT_SHA=$(git rev-parse HEAD)\nDB_HOST=db\nLAST=1\n",
+		},
+		{
+			name: "insert after the last line without a line break",
+			src:  "A=1\nB=2",
+			edit: func(f *edit.File) {
+				f.InsertAfter(f.Lookup("B")[0], "C", "3")
+				f.Set(f.Lookup("B")[0], "two")
+			},
+			expected: "A=1\nB=two\nC=3\n",
+		},
+		{
+			name: "append to empty file",
+			src:  "",
+			edit: func(f *edit.File) {
+				f.Append("A", "1")
+			},
+			expected: "A=1\n",
+		},
+		{
+			name: "set the last line without a line break",
+			src:  "A=1\nB=2",
+			edit: func(f *edit.File) {
+				f.Set(f.Lookup("B")[0], "3")
+			},
+			expected: "A=1\nB=3",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := edit.Parse([]byte(tc.src))
+			require.NoError(t, err)
+
+			tc.edit(f)
+			assert.Equal(t, tc.expected, string(f.Bytes()))
+		})
+	}
+}
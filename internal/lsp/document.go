@@ -0,0 +1,256 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/scanner"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// document is an .env file opened in the editor.
+type document struct {
+	uri   string
+	text  string
+	lines []int // the offsets of the lines
+
+	assignments []assignment // nil if the document cannot be parsed
+	references  []reference
+}
+
+// assignment is an assignment of a variable in the document.
+type assignment struct {
+	name       string
+	start, end int // the span of the name
+}
+
+// reference is a ${NAME} or ${NAME:-default} reference in a value.
+type reference struct {
+	name       string
+	start, end int // the span of the reference, including "${" and "}"
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lines: []int{0}}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+
+	_ = d.analyze() // the errors are reported by the linter, see Server.diagnostics
+
+	return d
+}
+
+// analyze parses the document and finds its assignments and references.
+func (d *document) analyze() error {
+	statement, err := parser.New(scanner.New(d.text)).Parse()
+	if err != nil {
+		return err
+	}
+
+	file, ok := statement.(*ast.FileStatement)
+	if !ok {
+		return fmt.Errorf("unexpected statement: %T", statement)
+	}
+
+	d.parse(file)
+
+	return nil
+}
+
+func (d *document) parse(file *ast.FileStatement) {
+	for _, stmt := range file.Statements {
+		switch s := stmt.(type) {
+		case *ast.AssignStatement:
+			d.assign(s.Name, s.Pos)
+		case *ast.HeredocStatement:
+			d.assign(s.Name, s.Pos)
+		}
+	}
+
+	s := scanner.New(d.text)
+
+	for {
+		tok := s.NextToken()
+		if tok.Type == token.EOF || tok.Type == token.Illegal {
+			break
+		}
+
+		if tok.Type == token.Variable {
+			name := tok.Literal
+			if i := strings.Index(name, ":-"); i >= 0 {
+				name = name[:i]
+			}
+
+			d.references = append(d.references, reference{name: name, start: tok.Offset, end: tok.Offset + tok.Length})
+		}
+	}
+}
+
+func (d *document) assign(name string, pos token.Position) {
+	start := d.offsetOf(pos)
+	d.assignments = append(d.assignments, assignment{name: name, start: start, end: start + len(name)})
+}
+
+// filename returns the path of the document, or an empty string if it is not a file.
+func (d *document) filename() string {
+	u, err := url.Parse(d.uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+
+	return u.Path
+}
+
+// keys returns the names of the variables assigned in the document in the order of their first assignment.
+func (d *document) keys() []string {
+	var keys []string
+
+	seen := make(map[string]bool)
+
+	for _, a := range d.assignments {
+		if !seen[a.name] {
+			seen[a.name] = true
+			keys = append(keys, a.name)
+		}
+	}
+
+	return keys
+}
+
+// referenceAt returns the reference that contains the offset.
+func (d *document) referenceAt(offset int) (reference, bool) {
+	for _, ref := range d.references {
+		if ref.start <= offset && offset < ref.end {
+			return ref, true
+		}
+	}
+
+	return reference{}, false
+}
+
+// assignmentAt returns the assignment whose name contains the offset.
+func (d *document) assignmentAt(offset int) (assignment, bool) {
+	for _, a := range d.assignments {
+		if a.start <= offset && offset <= a.end {
+			return a, true
+		}
+	}
+
+	return assignment{}, false
+}
+
+// definition returns the assignment the reference expands to: the last assignment of the variable before
+// the assignment that contains the reference, or, for a forward reference, the last assignment of the variable
+// in the document. A variable that references itself and is not assigned before has no definition in the document.
+func (d *document) definition(ref reference) (assignment, bool) {
+	owner := -1
+
+	for i, a := range d.assignments {
+		if a.start < ref.start {
+			owner = i
+		}
+	}
+
+	before, last := -1, -1
+
+	for i, a := range d.assignments {
+		if a.name != ref.name {
+			continue
+		}
+
+		if i < owner {
+			before = i
+		}
+
+		last = i
+	}
+
+	def := before
+	if def < 0 && (owner < 0 || d.assignments[owner].name != ref.name) {
+		def = last
+	}
+
+	if def < 0 {
+		return assignment{}, false
+	}
+
+	return d.assignments[def], true
+}
+
+// offsetOf returns the offset of the position of the scanner, which counts the columns in bytes.
+func (d *document) offsetOf(pos token.Position) int {
+	if pos.Line < 1 || pos.Line > len(d.lines) {
+		return len(d.text)
+	}
+
+	if offset := d.lines[pos.Line-1] + pos.Column - 1; offset < len(d.text) {
+		return offset
+	}
+
+	return len(d.text)
+}
+
+// offset returns the offset of the position of the client, which counts the characters in UTF-16 code units.
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+
+	offset := d.lines[pos.Line]
+
+	for units := 0; units < pos.Character && offset < len(d.text) && d.text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		units += utf16Len(r)
+		offset += size
+	}
+
+	return offset
+}
+
+// position returns the position of the offset for the client.
+func (d *document) position(offset int) Position {
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+
+	units := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		units += utf16Len(r)
+	}
+
+	return Position{Line: line, Character: units}
+}
+
+// span returns the range between the offsets.
+func (d *document) span(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// lineEnd returns the offset of the end of the line that contains the offset, before the line break.
+func (d *document) lineEnd(offset int) int {
+	if i := strings.IndexByte(d.text[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+
+	return len(d.text)
+}
+
+// utf16Len returns the number of the UTF-16 code units that encode the rune.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2 // a surrogate pair
+	}
+
+	return 1
+}
@@ -0,0 +1,458 @@
+// Package lsp implements a Language Server Protocol server for the .env files, that talks to the editor
+// over a pair of streams, such as the standard input and output of the process.
+//
+// The server publishes the diagnostics of the parser and the linter, shows the values of the variables on hover
+// (the secrets are redacted), goes to the definition of a ${NAME} reference, completes the names of the variables
+// declared in the schema, lists the variables as the document symbols, and formats the documents.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/format"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/internal/token"
+	"github.com/youla-dev/godenv/lint"
+	"github.com/youla-dev/godenv/schema"
+)
+
+const (
+	serverName    = "godenv"
+	exampleSchema = ".env.example"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client asks the server to exit without shutting it down first.
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Option configures the Server.
+type Option func(*options)
+
+type options struct {
+	schema string
+	lookup func(string) (string, bool)
+}
+
+// WithSchema takes the names of the variables to complete from the schema file.
+// By default, the schema is the .env.example file next to the document.
+func WithSchema(filename string) Option {
+	return func(o *options) {
+		o.schema = filename
+	}
+}
+
+// WithLookup expands the ${NAME} references to the variables that are not assigned in the document
+// with the values returned by lookup, e.g. os.LookupEnv. By default, such references expand to empty strings.
+func WithLookup(lookup func(name string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookup = lookup
+	}
+}
+
+// Server is a language server of the .env files. The zero value is not usable, see NewServer.
+type Server struct {
+	o        *options
+	linter   *lint.Linter
+	docs     map[string]*document // by URI
+	conn     *conn
+	shutdown bool
+}
+
+// NewServer returns a Server configured by the options.
+func NewServer(opts ...Option) *Server {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	linter, _ := lint.New() // all the rules are known
+
+	return &Server{
+		o:      o,
+		linter: linter,
+		docs:   make(map[string]*document),
+	}
+}
+
+// Serve reads the messages of the client from r and writes the responses to w until the client asks the server
+// to exit, or r ends. The messages are handled one by one, in the order they are received.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			var rpcErr *responseError
+			if errors.As(err, &rpcErr) {
+				if err := s.conn.write(&response{JSONRPC: "2.0", Error: rpcErr}); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+
+			return nil
+		}
+
+		result, err := s.handle(msg)
+
+		var rpcErr *responseError
+		if err != nil && !errors.As(err, &rpcErr) {
+			return err // the client cannot be written to
+		}
+
+		if msg.ID == nil {
+			continue // a notification has no response
+		}
+
+		resp := &response{JSONRPC: "2.0", ID: msg.ID, Result: result}
+		if rpcErr != nil {
+			resp.Result, resp.Error = nil, rpcErr
+		}
+
+		if err := s.conn.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles the request or the notification and returns the result of the request.
+func (s *Server) handle(msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+
+		if n := len(params.ContentChanges); n > 0 {
+			return nil, s.open(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+
+		delete(s.docs, params.TextDocument.URI)
+
+		return nil, s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/hover":
+		return s.handlePosition(msg, func(d *document, offset int) interface{} { return s.hover(d, offset) })
+	case "textDocument/definition":
+		return s.handlePosition(msg, func(d *document, offset int) interface{} { return s.definition(d, offset) })
+	case "textDocument/completion":
+		return s.handlePosition(msg, func(d *document, offset int) interface{} { return s.completion(d, offset) })
+	case "textDocument/documentSymbol":
+		return s.handleDocument(msg, func(d *document) interface{} { return s.symbols(d) })
+	case "textDocument/formatting":
+		return s.handleDocument(msg, func(d *document) interface{} { return s.format(d) })
+	}
+
+	if msg.ID == nil {
+		return nil, nil // the unknown notifications are ignored
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// handlePosition handles the request about a position in an open document.
+// The result is null if the document is not open.
+func (s *Server) handlePosition(msg *message, h func(d *document, offset int) interface{}) (interface{}, error) {
+	var params TextDocumentPositionParams
+	if err := decode(msg.Params, &params); err != nil {
+		return nil, err
+	}
+
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+
+	return h(d, d.offset(params.Position)), nil
+}
+
+// handleDocument handles the request about an open document. The result is null if the document is not open.
+func (s *Server) handleDocument(msg *message, h func(d *document) interface{}) (interface{}, error) {
+	var params DocumentParams
+	if err := decode(msg.Params, &params); err != nil {
+		return nil, err
+	}
+
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+
+	return h(d), nil
+}
+
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return s.conn.write(&message{JSONRPC: "2.0", Method: method, Params: body})
+}
+
+func (s *Server) initialize() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           TextDocumentSyncFull,
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			CompletionProvider:         &CompletionOptions{TriggerCharacters: []string{"{"}},
+			DocumentSymbolProvider:     true,
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: serverName},
+	}
+}
+
+// open remembers the content of the document and publishes its diagnostics.
+func (s *Server) open(uri, text string) error {
+	d := newDocument(uri, text)
+	s.docs[uri] = d
+
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.diagnostics(d),
+	})
+}
+
+// diagnostics returns the findings of the linter. A finding spans to the end of its line.
+// The syntax errors are reported as errors, the other findings as warnings.
+func (s *Server) diagnostics(d *document) []Diagnostic {
+	findings := s.lint(d)
+	diagnostics := make([]Diagnostic, 0, len(findings))
+
+	for _, f := range findings {
+		start := d.offsetOf(token.Position{Line: f.Pos.Line, Column: f.Pos.Column})
+
+		severity := SeverityWarning
+		if f.Rule == lint.RuleParse {
+			severity = SeverityError
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.span(start, d.lineEnd(start)),
+			Severity: severity,
+			Code:     f.Rule,
+			Source:   serverName,
+			Message:  f.Message,
+		})
+	}
+
+	return diagnostics
+}
+
+// lint returns the findings of the linter.
+func (s *Server) lint(d *document) []lint.Finding {
+	return s.linter.Lint(d.filename(), []byte(d.text))
+}
+
+// hover shows the value of the variable under the cursor: the name of an assignment or a reference.
+// The value is the one the application reads from the document, the secrets are redacted.
+func (s *Server) hover(d *document, offset int) *Hover {
+	var name string
+
+	var start, end int
+
+	if ref, ok := d.referenceAt(offset); ok {
+		name, start, end = ref.name, ref.start, ref.end
+	} else if a, ok := d.assignmentAt(offset); ok {
+		name, start, end = a.name, a.start, a.end
+	} else {
+		return nil
+	}
+
+	var text strings.Builder
+
+	vars, err := godenv.Read(strings.NewReader(d.text), s.readOptions(d)...)
+	if err != nil {
+		fmt.Fprintf(&text, "`%s`: the value cannot be resolved: %v", name, err)
+	} else {
+		value, ok := vars.Lookup(name)
+		if !ok && s.o.lookup != nil {
+			value, ok = s.o.lookup(name)
+		}
+
+		switch {
+		case !ok:
+			fmt.Fprintf(&text, "`%s` is not set", name)
+		case vars.IsRedacted(name):
+			fmt.Fprintf(&text, "```dotenv\n%s=%s\n```", name, godenv.Redacted)
+		default:
+			fmt.Fprintf(&text, "```dotenv\n%s=%s\n```", name, quote(value))
+		}
+	}
+
+	if sch := s.schema(d); sch != nil {
+		if f := sch.Field(name); f != nil && f.Description != "" {
+			text.WriteString("\n\n" + f.Description)
+		}
+	}
+
+	r := d.span(start, end)
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: text.String()},
+		Range:    &r,
+	}
+}
+
+// readOptions returns the options the document is read with: the included files are resolved against
+// the directory of the document, as ReadFile does, if it is a file.
+func (s *Server) readOptions(d *document) []godenv.Option {
+	opts := []godenv.Option{godenv.WithLookup(s.o.lookup)}
+	if name := d.filename(); name != "" {
+		opts = append(opts, godenv.WithFilename(name))
+	}
+
+	return opts
+}
+
+// definition returns the assignment that the reference under the cursor expands to.
+func (s *Server) definition(d *document, offset int) *Location {
+	ref, ok := d.referenceAt(offset)
+	if !ok {
+		return nil
+	}
+
+	a, ok := d.definition(ref)
+	if !ok {
+		return nil
+	}
+
+	return &Location{URI: d.uri, Range: d.span(a.start, a.end)}
+}
+
+// completion suggests the names of the variables declared in the schema and assigned in the document.
+func (s *Server) completion(d *document, _ int) []CompletionItem {
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+
+	if sch := s.schema(d); sch != nil {
+		for _, f := range sch.Fields {
+			item := CompletionItem{Label: f.Name, Kind: CompletionItemKindVariable}
+
+			if f.Type != nil {
+				item.Detail = f.Type.String()
+			}
+
+			if f.Description != "" {
+				item.Documentation = &MarkupContent{Kind: "plaintext", Value: f.Description}
+			}
+
+			seen[f.Name] = true
+			items = append(items, item)
+		}
+	}
+
+	for _, key := range d.keys() {
+		if !seen[key] {
+			items = append(items, CompletionItem{Label: key, Kind: CompletionItemKindVariable})
+		}
+	}
+
+	return items
+}
+
+// symbols returns the assignments of the document.
+func (s *Server) symbols(d *document) []DocumentSymbol {
+	symbols := make([]DocumentSymbol, 0, len(d.assignments))
+
+	for _, a := range d.assignments {
+		r := d.span(a.start, a.end)
+		symbols = append(symbols, DocumentSymbol{Name: a.name, Kind: SymbolKindVariable, Range: r, SelectionRange: r})
+	}
+
+	return symbols
+}
+
+// format returns the edit that rewrites the document in the canonical form, see the format package.
+// A document that cannot be parsed is left as it is.
+func (s *Server) format(d *document) []TextEdit {
+	formatted, err := format.Source([]byte(d.text))
+	if err != nil || string(formatted) == d.text {
+		return []TextEdit{}
+	}
+
+	return []TextEdit{{Range: d.span(0, len(d.text)), NewText: string(formatted)}}
+}
+
+// schema returns the schema of the document, or nil if there is none or it is invalid.
+func (s *Server) schema(d *document) *schema.Schema {
+	name := s.o.schema
+
+	if name == "" {
+		filename := d.filename()
+		if filename == "" || filepath.Base(filename) == exampleSchema {
+			return nil
+		}
+
+		name = filepath.Join(filepath.Dir(filename), exampleSchema)
+	}
+
+	sch, err := schema.ParseFile(name)
+	if err != nil {
+		return nil
+	}
+
+	return sch
+}
+
+// quote returns the value in the .env notation.
+func quote(value string) string {
+	if quoted, ok := printer.Quote(value); ok {
+		return quoted
+	}
+
+	return strconv.Quote(value)
+}
@@ -0,0 +1,500 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv/internal/lsp"
+)
+
+// client is a fake editor that talks to the server over in-process pipes.
+type client struct {
+	t        *testing.T
+	w        io.WriteCloser
+	messages chan rawMessage
+	done     chan error
+	nextID   int
+
+	diagnostics map[string][]lsp.Diagnostic // the last published diagnostics by URI
+}
+
+type rawMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newClient(t *testing.T, opts ...lsp.Option) *client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		t:           t,
+		w:           clientOut,
+		messages:    make(chan rawMessage),
+		done:        make(chan error, 1),
+		diagnostics: make(map[string][]lsp.Diagnostic),
+	}
+
+	go func() {
+		c.done <- lsp.NewServer(opts...).Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	go func() {
+		defer close(c.messages)
+
+		r := textproto.NewReader(bufio.NewReader(clientIn))
+
+		for {
+			header, err := r.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+
+			length, _ := strconv.Atoi(header.Get("Content-Length"))
+			body := make([]byte, length)
+
+			if _, err := io.ReadFull(r.R, body); err != nil {
+				return
+			}
+
+			var msg rawMessage
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return
+			}
+
+			c.messages <- msg
+		}
+	}()
+
+	t.Cleanup(func() {
+		clientOut.Close()
+		clientIn.Close()
+	})
+
+	return c
+}
+
+func (c *client) send(msg interface{}) {
+	c.t.Helper()
+
+	body, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(c.t, err)
+}
+
+// call sends the request and decodes the result of the response into result.
+// It returns the error of the response, the notifications received meanwhile are remembered.
+func (c *client) call(method string, params, result interface{}) error {
+	c.t.Helper()
+
+	c.nextID++
+	id := c.nextID
+
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+
+	for {
+		msg := c.receive()
+		if msg.ID == nil || *msg.ID != id {
+			continue
+		}
+
+		if msg.Error != nil {
+			return fmt.Errorf("%d: %s", msg.Error.Code, msg.Error.Message)
+		}
+
+		if result != nil {
+			require.NoError(c.t, json.Unmarshal(msg.Result, result))
+		}
+
+		return nil
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// receive returns the next message of the server, handling the diagnostics.
+func (c *client) receive() rawMessage {
+	c.t.Helper()
+
+	select {
+	case msg, ok := <-c.messages:
+		require.True(c.t, ok, "the server closed the connection")
+
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params lsp.PublishDiagnosticsParams
+			require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+			c.diagnostics[params.URI] = params.Diagnostics
+		}
+
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("no message from the server")
+		return rawMessage{}
+	}
+}
+
+// open opens the document and waits for its diagnostics.
+func (c *client) open(uri, text string) []lsp.Diagnostic {
+	c.t.Helper()
+
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": lsp.TextDocumentItem{URI: uri, LanguageID: "dotenv", Version: 1, Text: text},
+	})
+
+	for {
+		if msg := c.receive(); msg.Method == "textDocument/publishDiagnostics" {
+			return c.diagnostics[uri]
+		}
+	}
+}
+
+func (c *client) at(uri string, line, character int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func (c *client) exit() error {
+	c.t.Helper()
+
+	c.notify("exit", nil)
+
+	select {
+	case err := <-c.done:
+		return err
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("the server did not exit")
+		return nil
+	}
+}
+
+func span(startLine, startChar, endLine, endChar int) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: startLine, Character: startChar},
+		End:   lsp.Position{Line: endLine, Character: endChar},
+	}
+}
+
+const doc = `HOST=localhost
+
+# The address of the API.
+API_URL="http://${HOST}:${PORT:-8080}"
+DB_PASSWORD=hunter2
+`
+
+func TestServer_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+
+	var res lsp.InitializeResult
+	require.NoError(t, c.call("initialize", map[string]interface{}{}, &res))
+	assert.Equal(t, lsp.TextDocumentSyncFull, res.Capabilities.TextDocumentSync)
+	assert.True(t, res.Capabilities.HoverProvider)
+	assert.True(t, res.Capabilities.DefinitionProvider)
+	assert.NotNil(t, res.Capabilities.CompletionProvider)
+	assert.True(t, res.Capabilities.DocumentSymbolProvider)
+	assert.True(t, res.Capabilities.DocumentFormattingProvider)
+	assert.Equal(t, "godenv", res.ServerInfo.Name)
+
+	c.notify("initialized", map[string]interface{}{})
+
+	err := c.call("workspace/symbol", map[string]interface{}{}, nil)
+	assert.EqualError(t, err, "-32601: method not found: workspace/symbol")
+
+	require.NoError(t, c.call("shutdown", nil, nil))
+	assert.NoError(t, c.exit())
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	assert.ErrorIs(t, c.exit(), lsp.ErrExitWithoutShutdown)
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	uri := "file:///project/.env"
+
+	assert.Empty(t, c.open(uri, doc))
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   lsp.TextDocumentIdentifier{URI: uri},
+		"contentChanges": []lsp.TextDocumentContentChangeEvent{{Text: "A=1\nA=2\nb=3\n"}},
+	})
+	c.receive()
+
+	diagnostics := c.diagnostics[uri]
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, "duplicate-key", diagnostics[0].Code)
+	assert.Equal(t, lsp.SeverityWarning, diagnostics[0].Severity)
+	assert.Equal(t, span(1, 0, 1, 3), diagnostics[0].Range)
+	assert.Equal(t, "lowercase-key", diagnostics[1].Code)
+	assert.Equal(t, "godenv", diagnostics[1].Source)
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   lsp.TextDocumentIdentifier{URI: uri},
+		"contentChanges": []lsp.TextDocumentContentChangeEvent{{Text: "A=1\nB=\"unterminated\n"}},
+	})
+	c.receive()
+
+	diagnostics = c.diagnostics[uri]
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "parse", diagnostics[0].Code)
+	assert.Equal(t, lsp.SeverityError, diagnostics[0].Severity)
+	assert.Equal(t, 1, diagnostics[0].Range.Start.Line)
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": lsp.TextDocumentIdentifier{URI: uri}})
+	c.receive()
+	assert.Empty(t, c.diagnostics[uri], "the diagnostics are cleared")
+}
+
+func TestServer_MisplacedByteOrderMark(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	uri := "file:///project/.env"
+
+	diagnostics := c.open(uri, "A=x\uFEFF\nB=1\n")
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "parse", diagnostics[0].Code)
+	assert.Equal(t, lsp.SeverityError, diagnostics[0].Severity)
+	assert.Equal(t, 0, diagnostics[0].Range.Start.Line)
+
+	var hover *lsp.Hover
+	require.NoError(t, c.call("textDocument/hover", c.at(uri, 1, 0), &hover), "the server keeps running")
+	assert.Nil(t, hover, "the document that cannot be parsed has no assignments")
+}
+
+func TestServer_Hover(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(name string) (string, bool) {
+		if name == "PORT" {
+			return "9090", true
+		}
+
+		return "", false
+	}
+
+	c := newClient(t, lsp.WithLookup(lookup))
+	uri := "file:///project/.env"
+	c.open(uri, doc)
+
+	tests := []struct {
+		name     string
+		line     int
+		char     int
+		expected string
+		rng      lsp.Range
+	}{
+		{
+			name:     "assignment",
+			line:     3,
+			char:     3,
+			expected: "```dotenv\nAPI_URL=http://localhost:9090\n```",
+			rng:      span(3, 0, 3, 7),
+		},
+		{
+			name:     "reference",
+			line:     3,
+			char:     18,
+			expected: "```dotenv\nHOST=localhost\n```",
+			rng:      span(3, 16, 3, 23),
+		},
+		{
+			name:     "lookup",
+			line:     3,
+			char:     26,
+			expected: "```dotenv\nPORT=9090\n```",
+			rng:      span(3, 24, 3, 37),
+		},
+		{
+			name:     "secret",
+			line:     4,
+			char:     0,
+			expected: "```dotenv\nDB_PASSWORD=***\n```",
+			rng:      span(4, 0, 4, 11),
+		},
+	}
+
+	for _, tt := range tests {
+		var hover lsp.Hover
+		require.NoError(t, c.call("textDocument/hover", c.at(uri, tt.line, tt.char), &hover), tt.name)
+		assert.Equal(t, "markdown", hover.Contents.Kind, tt.name)
+		assert.Equal(t, tt.expected, hover.Contents.Value, tt.name)
+		require.NotNil(t, hover.Range, tt.name)
+		assert.Equal(t, tt.rng, *hover.Range, tt.name)
+	}
+
+	var hover *lsp.Hover
+	require.NoError(t, c.call("textDocument/hover", c.at(uri, 2, 5), &hover))
+	assert.Nil(t, hover, "nothing to show in a comment")
+}
+
+func TestServer_HoverInclude(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.env"), []byte("HOST=example.com\n"), 0o600))
+
+	c := newClient(t)
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, ".env"))
+	c.open(uri, "#include common.env\nURL=http://${HOST}\n")
+
+	var hover lsp.Hover
+	require.NoError(t, c.call("textDocument/hover", c.at(uri, 1, 0), &hover))
+	assert.Equal(t, "```dotenv\nURL=http://example.com\n```", hover.Contents.Value,
+		"the include is resolved against the directory of the document")
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app"), 0o700))
+
+	uri = "file://" + filepath.ToSlash(filepath.Join(dir, "app", ".env"))
+	c.open(uri, "#include ../common.env\nURL=http://${HOST}\n")
+
+	require.NoError(t, c.call("textDocument/hover", c.at(uri, 1, 0), &hover))
+	assert.Equal(t, "```dotenv\nURL=http://example.com\n```", hover.Contents.Value,
+		"the include is resolved outside of the directory of the document, as ReadFile does")
+}
+
+func TestServer_Definition(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	uri := "file:///project/.env"
+	c.open(uri, "HOST=old\nURL=\"${HOST}/${NEXT}\"\nHOST=new\nNEXT=1\nPATH=${PATH}:/opt\nPATH=\"${PATH}:/usr\"\nGREETING=\"🚀 ${HOST}\"\n")
+
+	tests := []struct {
+		name     string
+		line     int
+		char     int
+		expected *lsp.Location
+	}{
+		{name: "the previous assignment", line: 1, char: 6, expected: &lsp.Location{URI: uri, Range: span(0, 0, 0, 4)}},
+		{name: "forward reference", line: 1, char: 14, expected: &lsp.Location{URI: uri, Range: span(3, 0, 3, 4)}},
+		{name: "self-reference", line: 5, char: 7, expected: &lsp.Location{URI: uri, Range: span(4, 0, 4, 4)}},
+		{name: "external", line: 4, char: 6},
+		{name: "UTF-16", line: 6, char: 13, expected: &lsp.Location{URI: uri, Range: span(2, 0, 2, 4)}},
+		{name: "UTF-16 before the reference", line: 6, char: 12},
+		{name: "not a reference", line: 0, char: 1},
+	}
+
+	for _, tt := range tests {
+		var location *lsp.Location
+		require.NoError(t, c.call("textDocument/definition", c.at(uri, tt.line, tt.char), &location), tt.name)
+		assert.Equal(t, tt.expected, location, tt.name)
+	}
+}
+
+func TestServer_Completion(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	example := "# The address of the API.\n# @type url\nAPI_URL=http://localhost\n\nLOG_LEVEL=info\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env.example"), []byte(example), 0o600))
+
+	expected := []lsp.CompletionItem{
+		{
+			Label:         "API_URL",
+			Kind:          lsp.CompletionItemKindVariable,
+			Detail:        "url",
+			Documentation: &lsp.MarkupContent{Kind: "plaintext", Value: "The address of the API."},
+		},
+		{Label: "LOG_LEVEL", Kind: lsp.CompletionItemKindVariable, Detail: "string"},
+		{Label: "DEBUG", Kind: lsp.CompletionItemKindVariable},
+	}
+
+	t.Run(".env.example next to the document", func(t *testing.T) {
+		t.Parallel()
+
+		c := newClient(t)
+		uri := "file://" + filepath.ToSlash(filepath.Join(dir, ".env"))
+		c.open(uri, "DEBUG=true\nLOG_LEVEL=debug\n")
+
+		var items []lsp.CompletionItem
+		require.NoError(t, c.call("textDocument/completion", c.at(uri, 2, 0), &items))
+		assert.Equal(t, expected, items)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+
+		c := newClient(t, lsp.WithSchema(filepath.Join(dir, ".env.example")))
+		uri := "untitled:Untitled-1"
+		c.open(uri, "DEBUG=true\n")
+
+		var items []lsp.CompletionItem
+		require.NoError(t, c.call("textDocument/completion", c.at(uri, 1, 0), &items))
+		assert.Equal(t, expected, items)
+	})
+}
+
+func TestServer_DocumentSymbol(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	uri := "file:///project/.env"
+	c.open(uri, "# 🚀 launch\nA=1\nJSON<<EOF\n{}\nEOF\nB='🚀'\n")
+
+	var symbols []lsp.DocumentSymbol
+	require.NoError(t, c.call("textDocument/documentSymbol", map[string]interface{}{
+		"textDocument": lsp.TextDocumentIdentifier{URI: uri},
+	}, &symbols))
+
+	assert.Equal(t, []lsp.DocumentSymbol{
+		{Name: "A", Kind: lsp.SymbolKindVariable, Range: span(1, 0, 1, 1), SelectionRange: span(1, 0, 1, 1)},
+		{Name: "JSON", Kind: lsp.SymbolKindVariable, Range: span(2, 0, 2, 4), SelectionRange: span(2, 0, 2, 4)},
+		{Name: "B", Kind: lsp.SymbolKindVariable, Range: span(5, 0, 5, 1), SelectionRange: span(5, 0, 5, 1)},
+	}, symbols)
+}
+
+func TestServer_Formatting(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	uri := "file:///project/.env"
+	params := map[string]interface{}{"textDocument": lsp.TextDocumentIdentifier{URI: uri}}
+
+	c.open(uri, "\n\nA=\"plain\"\n\n\nB=\"it s\"\n")
+
+	var edits []lsp.TextEdit
+	require.NoError(t, c.call("textDocument/formatting", params, &edits))
+	assert.Equal(t, []lsp.TextEdit{{Range: span(0, 0, 6, 0), NewText: "A=plain\n\nB='it s'\n"}}, edits)
+
+	c.open(uri, "A=plain\n")
+	require.NoError(t, c.call("textDocument/formatting", params, &edits))
+	assert.Empty(t, edits, "the document is formatted")
+
+	c.open(uri, "A=\"unterminated\n")
+	require.NoError(t, c.call("textDocument/formatting", params, &edits))
+	assert.Empty(t, edits, "the document cannot be parsed")
+}
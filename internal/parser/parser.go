@@ -3,16 +3,33 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/youla-dev/godenv/internal/ast"
 	"github.com/youla-dev/godenv/internal/token"
 )
 
+const includeDirective = "#include"
+
 // Scanner converts a sequence of characters into a sequence of tokens.
 type Scanner interface {
 	NextToken() token.Token
 }
 
+// Error is a syntax error with the position of the offending token.
+type Error struct {
+	Pos token.Position
+	Msg string
+	// SafeMsg is Msg without the text of the offending token, unless it is a name or whitespace:
+	// the token may be a part of a value that holds a secret.
+	SafeMsg string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
 // Parser takes a Scanner and builds an abstract syntax tree.
 type Parser struct {
 	scanner Scanner
@@ -48,69 +65,202 @@ func (p *Parser) Parse() (ast.Statement, error) {
 }
 
 func (p *Parser) parseStatement() (ast.Statement, error) {
-	p.skipBlankLine()
+	pos := p.token.Pos
+	p.skipSpace()
 
 	switch p.token.Type {
+	case token.NewLine, token.EOF:
+		return p.parseBlankLine(pos)
 	case token.Identifier:
 		return p.parseAssignStatement()
 	case token.Comment:
 		return p.parseCommentStatement()
 	default:
-		return nil, fmt.Errorf("unexpected statement: %s(%q)", p.token.Type, p.token.Literal)
+		return nil, p.unexpected("unexpected statement: %s(%q)")
 	}
 }
 
+func (p *Parser) parseBlankLine(pos token.Position) (ast.Statement, error) {
+	p.nextToken()
+	return &ast.BlankLineStatement{Pos: pos}, nil
+}
+
 func (p *Parser) parseCommentStatement() (ast.Statement, error) {
+	if path, ok := includePath(p.token.Literal); ok {
+		return p.parseIncludeStatement(path)
+	}
+
 	comment := &ast.CommentStatement{
 		Value: p.token.Literal,
+		Pos:   p.token.Pos,
 	}
 
-	p.nextToken()
+	p.skipLineEnd()
 
 	return comment, nil
 }
 
+func (p *Parser) parseIncludeStatement(path string) (ast.Statement, error) {
+	if path == "" {
+		return nil, p.errorf("empty include path")
+	}
+
+	include := &ast.IncludeStatement{
+		Path: path,
+		Pos:  p.token.Pos,
+	}
+
+	p.skipLineEnd()
+
+	return include, nil
+}
+
 func (p *Parser) parseAssignStatement() (ast.Statement, error) {
-	name := p.token.Literal
+	name, pos := p.token.Literal, p.token.Pos
 	p.nextToken()
 
 	switch p.token.Type {
 	case token.NewLine, token.EOF:
-		return p.parseNakedAssign(name)
+		return p.parseNakedAssign(name, pos)
 	case token.Assign:
 		p.nextToken()
 
 		switch p.token.Type {
 		case token.NewLine, token.EOF:
-			return p.parseNakedAssign(name)
+			return p.parseNakedAssign(name, pos)
 		case token.Value, token.RawValue:
-			return p.parseCompleteAssign(name)
+			return p.parseCompleteAssign(name, pos)
 		}
+	case token.Heredoc:
+		return p.parseHeredoc(name, pos)
 	}
 
-	return nil, fmt.Errorf("unexpected token: %s(%s)", p.token.Type, p.token.Literal)
+	return nil, p.unexpected("unexpected token: %s(%s)")
 }
 
-func (p *Parser) parseNakedAssign(name string) (ast.Statement, error) {
+func (p *Parser) parseNakedAssign(name string, pos token.Position) (ast.Statement, error) {
 	p.nextToken()
-	return &ast.AssignStatement{Name: name}, nil
+	return &ast.AssignStatement{Name: name, Pos: pos}, nil
 }
 
-func (p *Parser) parseCompleteAssign(name string) (ast.Statement, error) {
-	value := p.token.Literal
+func (p *Parser) parseCompleteAssign(name string, pos token.Position) (ast.Statement, error) {
+	assign := &ast.AssignStatement{Name: name, Value: p.token.Literal, Quoted: p.token.Quoted, Pos: pos}
 	p.nextToken()
 
+	if p.token.Type == token.Command || p.token.Type == token.Variable {
+		if err := p.parseSubstitutions(assign); err != nil {
+			return nil, err
+		}
+	}
+
 	switch p.token.Type {
 	case token.NewLine, token.EOF:
 		p.nextToken()
-		return &ast.AssignStatement{Name: name, Value: value}, nil
+		return assign, nil
 	default:
-		return nil, fmt.Errorf("unexpected token: %s(%s)", p.token.Type, p.token.Literal)
+		return nil, p.unexpected("unexpected token: %s(%s)")
 	}
 }
 
-func (p *Parser) skipBlankLine() {
-	for p.token.Type == token.NewLine || p.token.Type == token.Space {
+// parseSubstitutions parses the command substitutions and the variable references of the value and the text
+// between them. Each token.Command or token.Variable is followed by token.Value with the text after it.
+func (p *Parser) parseSubstitutions(assign *ast.AssignStatement) error {
+	assign.Parts = appendText(nil, assign.Value)
+
+	for p.token.Type == token.Command || p.token.Type == token.Variable {
+		substitution := p.token
+		p.nextToken()
+
+		if p.token.Type != token.Value {
+			return p.unexpected("unexpected token: %s(%s)")
+		}
+
+		switch substitution.Type {
+		case token.Command:
+			assign.Parts = append(assign.Parts, &ast.CommandSubstitution{Command: substitution.Literal})
+			assign.Value += "$(" + substitution.Literal + ")"
+		default:
+			ref := &ast.VariableReference{Name: substitution.Literal, Pos: substitution.Pos}
+			if i := strings.Index(ref.Name, ":-"); i >= 0 {
+				ref.Name, ref.Default, ref.HasDefault = ref.Name[:i], ref.Name[i+2:], true
+			}
+
+			assign.Parts = append(assign.Parts, ref)
+			assign.Value += "${" + substitution.Literal + "}"
+		}
+
+		assign.Parts = appendText(assign.Parts, p.token.Literal)
+		assign.Value += p.token.Literal
+
+		p.nextToken()
+	}
+
+	return nil
+}
+
+func (p *Parser) parseHeredoc(name string, pos token.Position) (ast.Statement, error) {
+	header := strings.TrimPrefix(p.token.Literal, "<<")
+	p.nextToken()
+
+	if p.token.Type != token.RawValue {
+		return nil, p.unexpected("unexpected token: %s(%s)")
+	}
+
+	heredoc := &ast.HeredocStatement{
+		Name:      name,
+		Delimiter: strings.TrimPrefix(header, "~"),
+		Indented:  strings.HasPrefix(header, "~"),
+		Value:     p.token.Literal,
+		Pos:       pos,
+	}
+
+	p.nextToken()
+
+	switch p.token.Type {
+	case token.NewLine, token.EOF:
+		p.nextToken()
+		return heredoc, nil
+	default:
+		return nil, p.unexpected("unexpected token: %s(%s)")
+	}
+}
+
+// includePath returns the path of the "#include <path>" directive. The path may be quoted.
+// If the comment is not an include directive, includePath returns false.
+func includePath(comment string) (string, bool) {
+	rest := strings.TrimPrefix(comment, includeDirective)
+	if rest == comment || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+
+	path := strings.TrimSpace(rest)
+
+	if len(path) >= 2 && (path[0] == '"' || path[0] == '\'') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	}
+
+	return path, true
+}
+
+func appendText(parts []ast.ValuePart, text string) []ast.ValuePart {
+	if text == "" {
+		return parts
+	}
+
+	return append(parts, &ast.Text{Value: text})
+}
+
+func (p *Parser) skipSpace() {
+	for p.token.Type == token.Space {
+		p.nextToken()
+	}
+}
+
+// skipLineEnd skips the current token, that takes the rest of the line, and the line break after it.
+func (p *Parser) skipLineEnd() {
+	p.nextToken()
+
+	if p.token.Type == token.NewLine {
 		p.nextToken()
 	}
 }
@@ -118,3 +268,31 @@ func (p *Parser) skipBlankLine() {
 func (p *Parser) nextToken() {
 	p.token = p.scanner.NextToken()
 }
+
+// errorf returns an error at the position of the current token.
+func (p *Parser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+
+	return &Error{
+		Pos:     p.token.Pos,
+		Msg:     msg,
+		SafeMsg: msg,
+	}
+}
+
+// unexpected returns an error about the current token. The format takes the type and the literal of the token.
+func (p *Parser) unexpected(format string) error {
+	err := &Error{
+		Pos: p.token.Pos,
+		Msg: fmt.Sprintf(format, p.token.Type, p.token.Literal),
+	}
+
+	switch p.token.Type {
+	case token.Identifier, token.Space, token.NewLine, token.Assign, token.EOF:
+		err.SafeMsg = err.Msg
+	default:
+		err.SafeMsg = fmt.Sprintf(format[:strings.IndexByte(format, '(')], p.token.Type)
+	}
+
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/youla-dev/godenv/internal/ast"
 	"github.com/youla-dev/godenv/internal/parser"
 	"github.com/youla-dev/godenv/internal/scanner"
+	"github.com/youla-dev/godenv/internal/token"
 )
 
 func TestParser_Parse(t *testing.T) {
@@ -28,6 +29,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "name",
 							Value: "value",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -38,8 +40,10 @@ func TestParser_Parse(t *testing.T) {
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
 						&ast.AssignStatement{
-							Name:  "name",
-							Value: "value",
+							Name:   "name",
+							Value:  "value",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -50,8 +54,10 @@ func TestParser_Parse(t *testing.T) {
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
 						&ast.AssignStatement{
-							Name:  "name",
-							Value: "value",
+							Name:   "name",
+							Value:  "value",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -64,6 +70,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "name",
 							Value: "",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -76,6 +83,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "name",
 							Value: "",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -85,10 +93,36 @@ func TestParser_Parse(t *testing.T) {
 				input: "\n\n\n\nname=\n\n\n",
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
+						&ast.BlankLineStatement{Pos: token.Position{Line: 1, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 2, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 3, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 4, Column: 1}},
+						&ast.AssignStatement{
+							Name:  "name",
+							Value: "",
+							Pos:   token.Position{Line: 5, Column: 1},
+						},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 6, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 7, Column: 1}},
+					},
+				},
+			},
+			{
+				name:  "variable with blank and whitespace-only lines",
+				input: "\n\n\n\nname=\n\n  \n",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.BlankLineStatement{Pos: token.Position{Line: 1, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 2, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 3, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 4, Column: 1}},
 						&ast.AssignStatement{
 							Name:  "name",
 							Value: "",
+							Pos:   token.Position{Line: 5, Column: 1},
 						},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 6, Column: 1}},
+						&ast.BlankLineStatement{Pos: token.Position{Line: 7, Column: 1}},
 					},
 				},
 			},
@@ -100,14 +134,17 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "DEBUG_HTTP_ADDR",
 							Value: ":9090",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 						&ast.AssignStatement{
 							Name:  "DEBUG_HTTP_IDLE_TIMEOUT",
 							Value: "0s",
+							Pos:   token.Position{Line: 2, Column: 1},
 						},
 						&ast.AssignStatement{
 							Name:  "JAEGER_AGENT_ENDPOINT",
 							Value: "jaeger-otlp-agent:6831",
+							Pos:   token.Position{Line: 3, Column: 1},
 						},
 					},
 				},
@@ -119,13 +156,16 @@ func TestParser_Parse(t *testing.T) {
 					Statements: []ast.Statement{
 						&ast.CommentStatement{
 							Value: "# comment 1",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 						&ast.AssignStatement{
 							Name:  "DEBUG_HTTP_ADDR",
 							Value: ":9090",
+							Pos:   token.Position{Line: 2, Column: 1},
 						},
 						&ast.CommentStatement{
 							Value: "# comment 2",
+							Pos:   token.Position{Line: 3, Column: 1},
 						},
 					},
 				},
@@ -136,8 +176,10 @@ func TestParser_Parse(t *testing.T) {
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
 						&ast.AssignStatement{
-							Name:  "FOO",
-							Value: "bar\nbaz",
+							Name:   "FOO",
+							Value:  "bar\nbaz",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -150,6 +192,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "FOO",
 							Value: "bar\nbaz",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -160,8 +203,10 @@ func TestParser_Parse(t *testing.T) {
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
 						&ast.AssignStatement{
-							Name:  "FOO",
-							Value: "'d'",
+							Name:   "FOO",
+							Value:  "'d'",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -174,6 +219,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "FOO",
 							Value: "foobar=",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -187,6 +233,7 @@ func TestParser_Parse(t *testing.T) {
 						&ast.AssignStatement{
 							Name:  "FOO",
 							Value: "bar # this is foo",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -196,9 +243,193 @@ func TestParser_Parse(t *testing.T) {
 				input: `FOO="bar#baz"`,
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:   "FOO",
+							Value:  "bar#baz",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "line continuation in naked value",
+				input: "JVM_OPTS=-Xms512m \\\n-Xmx2g\nLOG_LEVEL=info",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:  "JVM_OPTS",
+							Value: "-Xms512m -Xmx2g",
+							Pos:   token.Position{Line: 1, Column: 1},
+						},
+						&ast.AssignStatement{
+							Name:  "LOG_LEVEL",
+							Value: "info",
+							Pos:   token.Position{Line: 3, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "line continuation in double quoted value",
+				input: "JVM_OPTS=\"-Xms512m \\\n-Xmx2g\"",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:   "JVM_OPTS",
+							Value:  "-Xms512m -Xmx2g",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "heredoc",
+				input: "KEY<<EOF\n{\"type\": \"service_account\"}\nEOF\nNEXT=value",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.HeredocStatement{
+							Name:      "KEY",
+							Delimiter: "EOF",
+							Value:     `{"type": "service_account"}`,
+							Pos:       token.Position{Line: 1, Column: 1},
+						},
+						&ast.AssignStatement{
+							Name:  "NEXT",
+							Value: "value",
+							Pos:   token.Position{Line: 4, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "indented heredoc",
+				input: "KEY<<~JSON\n  {\n    \"a\": 1\n  }\n  JSON",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.HeredocStatement{
+							Name:      "KEY",
+							Delimiter: "JSON",
+							Indented:  true,
+							Value:     "{\n  \"a\": 1\n}",
+							Pos:       token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "heredoc header after equal sign is a value",
+				input: "FOO=<<EOF",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:  "FOO",
+							Value: "<<EOF",
+							Pos:   token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "command substitution",
+				input: `GIT_SHA="sha-$(git rev-parse HEAD)"`,
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:  "GIT_SHA",
+							Value: "sha-$(git rev-parse HEAD)",
+							Parts: []ast.ValuePart{
+								&ast.Text{Value: "sha-"},
+								&ast.CommandSubstitution{Command: "git rev-parse HEAD"},
+							},
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "variable references",
+				input: `URL="http://${HOST}:${PORT:-8080}/$(whoami)"`,
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:  "URL",
+							Value: "http://${HOST}:${PORT:-8080}/$(whoami)",
+							Parts: []ast.ValuePart{
+								&ast.Text{Value: "http://"},
+								&ast.VariableReference{Name: "HOST", Pos: token.Position{Line: 1, Column: 13}},
+								&ast.Text{Value: ":"},
+								&ast.VariableReference{Name: "PORT", Default: "8080", HasDefault: true, Pos: token.Position{Line: 1, Column: 21}},
+								&ast.Text{Value: "/"},
+								&ast.CommandSubstitution{Command: "whoami"},
+							},
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "several command substitutions",
+				input: `BUILD=$(date)\t$(whoami)`,
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.AssignStatement{
+							Name:  "BUILD",
+							Value: "$(date)\t$(whoami)",
+							Parts: []ast.ValuePart{
+								&ast.CommandSubstitution{Command: "date"},
+								&ast.Text{Value: "\t"},
+								&ast.CommandSubstitution{Command: "whoami"},
+							},
+							Pos: token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "include directive",
+				input: "#include ./common.env\nFOO=bar\n#include \"local env/.env\"",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.IncludeStatement{
+							Path: "./common.env",
+							Pos:  token.Position{Line: 1, Column: 1},
+						},
 						&ast.AssignStatement{
 							Name:  "FOO",
-							Value: "bar#baz",
+							Value: "bar",
+							Pos:   token.Position{Line: 2, Column: 1},
+						},
+						&ast.IncludeStatement{
+							Path: "local env/.env",
+							Pos:  token.Position{Line: 3, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "comment that looks like include directive",
+				input: "#includes are resolved first\n",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.CommentStatement{
+							Value: "#includes are resolved first",
+							Pos:   token.Position{Line: 1, Column: 1},
+						},
+					},
+				},
+			},
+			{
+				name:  "comment with a space before include",
+				input: "# include ./common.env\n",
+				expected: &ast.FileStatement{
+					Statements: []ast.Statement{
+						&ast.CommentStatement{
+							Value: "# include ./common.env",
+							Pos:   token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -209,8 +440,10 @@ func TestParser_Parse(t *testing.T) {
 				expected: &ast.FileStatement{
 					Statements: []ast.Statement{
 						&ast.AssignStatement{
-							Name:  "FOO",
-							Value: "bar#baz",
+							Name:   "FOO",
+							Value:  "bar#baz",
+							Quoted: true,
+							Pos:    token.Position{Line: 1, Column: 1},
 						},
 					},
 				},
@@ -258,6 +491,26 @@ func TestParser_Parse(t *testing.T) {
 				name:  "leading whitespace",
 				input: "  FOO=bar",
 			},
+			{
+				name:  "line continuation in single quoted value",
+				input: "FOO='bar\\\nbaz'",
+			},
+			{
+				name:  "command substitution in not paired quotes",
+				input: `FOO="$(date)`,
+			},
+			{
+				name:  "unterminated heredoc",
+				input: "FOO<<EOF\nbar",
+			},
+			{
+				name:  "heredoc after space",
+				input: "FOO <<EOF\nbar\nEOF",
+			},
+			{
+				name:  "empty include path",
+				input: "#include \"\"",
+			},
 		}
 
 		for _, tt := range tests {
@@ -0,0 +1,294 @@
+// Package printer implements printing of the syntax trees of the .env file.
+package printer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/youla-dev/godenv/internal/ast"
+)
+
+const defaultDelimiter = "EOF"
+
+// nolint:gochecknoglobals
+var escaper = strings.NewReplacer(
+	"\n", `\n`,
+	"\t", `\t`,
+	"\r", `\r`,
+	"\v", `\v`,
+	"\f", `\f`,
+)
+
+// Fprint prints the node to w in the .env format.
+// The node must be an *ast.FileStatement or one of the statements it contains.
+func Fprint(w io.Writer, node ast.Node) error {
+	bw := bufio.NewWriter(w)
+
+	switch n := node.(type) {
+	case *ast.FileStatement:
+		for _, stmt := range n.Statements {
+			if err := printStatement(bw, stmt); err != nil {
+				return err
+			}
+
+			bw.WriteByte('\n')
+		}
+	case ast.Statement:
+		if err := printStatement(bw, n); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported node: %T", node)
+	}
+
+	return bw.Flush()
+}
+
+// Assign returns a statement that assigns the value to the variable.
+// The value is written as a heredoc if it contains both line breaks and quotes,
+// or if no quoting can keep the value intact. A value that contains a carriage return is never written
+// as a heredoc, as the carriage returns at the ends of the heredoc lines are stripped when parsed back.
+func Assign(name, value string) ast.Statement {
+	_, ok := Quote(value)
+	if (ok && !(strings.Contains(value, "\n") && strings.ContainsAny(value, `"'`))) || strings.ContainsRune(value, '\r') {
+		return &ast.AssignStatement{Name: name, Value: value}
+	}
+
+	return &ast.HeredocStatement{
+		Name:      name,
+		Delimiter: Delimiter(value),
+		Value:     value,
+	}
+}
+
+// Quote returns the value in the most readable notation that keeps the value intact when it is parsed back:
+// unquoted, in single quotes, or in double quotes with the escape sequences.
+// If the value cannot be written in a single line, Quote returns false.
+func Quote(value string) (string, bool) {
+	switch {
+	case isPlain(value):
+		return value, true
+	case !strings.ContainsRune(value, '\'') && !containsControl(value, '\t'):
+		return "'" + value + "'", true
+	case !strings.ContainsRune(value, '"') && !containsEscapeSequence(value) && !containsControl(value, '\n', '\t', '\r', '\v', '\f'):
+		return `"` + escaper.Replace(value) + `"`, true
+	default:
+		return "", false
+	}
+}
+
+// Writable reports whether the value can be written, so it is kept intact when parsed back.
+// A value that cannot be quoted is written as a heredoc, which cannot keep a carriage return.
+func Writable(value string) bool {
+	_, ok := Quote(value)
+
+	return ok || !strings.ContainsRune(value, '\r')
+}
+
+// Delimiter returns a heredoc delimiter that does not clash with any line of the value.
+func Delimiter(value string) string {
+	lines := strings.Split(value, "\n")
+
+	for i := 0; ; i++ {
+		delimiter := defaultDelimiter
+		if i > 0 {
+			delimiter += strconv.Itoa(i)
+		}
+
+		if !containsLine(lines, delimiter) {
+			return delimiter
+		}
+	}
+}
+
+func printStatement(w *bufio.Writer, stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		if s.Parts != nil {
+			return printSubstitutions(w, s)
+		}
+
+		if !Writable(s.Value) {
+			return fmt.Errorf("variable %s: the value cannot be written", s.Name)
+		}
+
+		quoted, ok := Quote(s.Value)
+		if !ok {
+			return printStatement(w, Assign(s.Name, s.Value))
+		}
+
+		w.WriteString(s.Name + "=" + quoted)
+	case *ast.HeredocStatement:
+		printHeredoc(w, s)
+	case *ast.CommentStatement:
+		w.WriteString(s.Value)
+	case *ast.IncludeStatement:
+		return printInclude(w, s)
+	case *ast.BlankLineStatement:
+		// the line break is printed after the statement
+	default:
+		return fmt.Errorf("unsupported statement: %T", stmt)
+	}
+
+	return nil
+}
+
+// printSubstitutions prints the value that contains substitutions or variable references in double quotes,
+// so they are interpreted when parsed back.
+func printSubstitutions(w *bufio.Writer, s *ast.AssignStatement) error {
+	var value strings.Builder
+
+	for _, part := range s.Parts {
+		switch p := part.(type) {
+		case *ast.Text:
+			if strings.ContainsRune(p.Value, '"') || containsEscapeSequence(p.Value) ||
+				containsControl(p.Value, '\n', '\t', '\r', '\v', '\f') {
+				return fmt.Errorf("variable %s: the value cannot be written with substitutions", s.Name)
+			}
+
+			value.WriteString(escaper.Replace(p.Value))
+		case *ast.CommandSubstitution:
+			value.WriteString("$(" + p.Command + ")")
+		case *ast.VariableReference:
+			if strings.ContainsRune(p.Default, '"') || containsEscapeSequence(p.Default) ||
+				containsControl(p.Default, '\n', '\t', '\r', '\v', '\f') {
+				return fmt.Errorf("variable %s: the default of ${%s} cannot be written in double quotes", s.Name, p.Name)
+			}
+
+			if p.HasDefault {
+				value.WriteString("${" + p.Name + ":-" + escaper.Replace(p.Default) + "}")
+			} else {
+				value.WriteString("${" + p.Name + "}")
+			}
+		default:
+			return fmt.Errorf("unsupported value part: %T", part)
+		}
+	}
+
+	w.WriteString(s.Name + `="` + value.String() + `"`)
+
+	return nil
+}
+
+// printInclude prints the include directive. The path is quoted if it contains whitespace characters or quotes.
+func printInclude(w *bufio.Writer, s *ast.IncludeStatement) error {
+	path := s.Path
+
+	switch {
+	case path != "" && !strings.ContainsAny(path, " \t\"'"):
+	case path != "" && !strings.ContainsRune(path, '"'):
+		path = `"` + path + `"`
+	case path != "" && !strings.ContainsRune(path, '\''):
+		path = "'" + path + "'"
+	default:
+		return fmt.Errorf("include path %q cannot be written", path)
+	}
+
+	w.WriteString("#include " + path)
+
+	return nil
+}
+
+func printHeredoc(w *bufio.Writer, s *ast.HeredocStatement) {
+	lines := strings.Split(s.Value, "\n")
+
+	delimiter := s.Delimiter
+	if delimiter == "" || containsLine(lines, delimiter) {
+		delimiter = Delimiter(s.Value)
+	}
+
+	indent, header := "", "<<"
+	if s.Indented && !hasCommonIndent(lines) {
+		indent, header = "  ", "<<~"
+	}
+
+	w.WriteString(s.Name + header + delimiter + "\n")
+
+	for _, line := range lines {
+		if line != "" {
+			w.WriteString(indent + line)
+		}
+
+		w.WriteByte('\n')
+	}
+
+	w.WriteString(indent + delimiter)
+}
+
+// isPlain reports whether the value can be written without quotes.
+func isPlain(value string) bool {
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_./:@,+%=", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsEscapeSequence reports whether the value contains a text that is interpreted inside double quotes:
+// an escape sequence (e.g. a backslash followed by "n"), a command substitution or a variable reference.
+func containsEscapeSequence(value string) bool {
+	for _, seq := range []string{`\n`, `\t`, `\r`, `\v`, `\f`, `$(`, `${`} {
+		if strings.Contains(value, seq) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsControl reports whether the value contains control characters except the allowed ones.
+func containsControl(value string, allowed ...rune) bool {
+	for _, r := range value {
+		if unicode.IsControl(r) && !containsRune(allowed, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, x := range runes {
+		if x == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasCommonIndent reports whether all non-blank lines start with a space or a tab.
+// Such lines cannot be written in the indented heredoc form, as the indentation is stripped when parsed back.
+func hasCommonIndent(lines []string) bool {
+	found := false
+
+	for _, line := range lines {
+		if strings.TrimLeft(line, " \t") == "" {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			return false
+		}
+
+		found = true
+	}
+
+	return found
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimLeft(l, " \t") == line {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,7 +1,7 @@
 package scanner
 
 import (
-	"strconv"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -23,18 +23,45 @@ const (
 	eof = -1     // eof indicates the end of the file.
 )
 
+// Mode controls the scanner behaviour.
+type Mode uint
+
+// The list of scanner modes.
+const (
+	// TrimContinuationIndent drops leading spaces and tabs of a line that continues a value
+	// after a backslash-newline sequence.
+	TrimContinuationIndent Mode = 1 << iota
+)
+
 // Scanner converts a sequence of characters into a sequence of tokens.
 type Scanner struct {
 	input      string
-	ch         rune // current character
-	prevOffset int  // position before current character
-	offset     int  // character offset
-	peekOffset int  // position after current character
+	mode       Mode
+	ch         rune  // current character
+	prevOffset int   // position before current character
+	offset     int   // character offset
+	peekOffset int   // position after current character
+	lines      []int // offsets of the first characters of the scanned lines
+
+	pending []token.Token // tokens that are already scanned but not returned yet
+
+	invalid     int // offset of the first invalid character that is read, or -1
+	invalidSize int // width of the invalid character in bytes
 }
 
 // New returns new Scanner.
 func New(input string) *Scanner {
-	s := &Scanner{input: input}
+	return NewWithMode(input, 0)
+}
+
+// NewWithMode returns new Scanner that behaves according to the given mode.
+func NewWithMode(input string, mode Mode) *Scanner {
+	s := &Scanner{
+		input:   input,
+		mode:    mode,
+		lines:   []int{0},
+		invalid: -1,
+	}
 
 	s.next()
 	if s.ch == bom {
@@ -50,19 +77,57 @@ func New(input string) *Scanner {
 // If the returned token is a literal (token.Identifier, token.Value, token.RawValue) or token.Comment,
 // the literal string has the corresponding value.
 //
+// If the returned token is token.Heredoc, the literal string is the heredoc header (e.g. "<<EOF"),
+// and the next token is token.RawValue with the lines of the value.
+//
+// If the returned token is token.Command, the literal string is the command of a $(command) substitution,
+// and the next token is token.Value with the rest of the value. The same holds for token.Variable,
+// the literal string of which is the text between the braces of a ${NAME} or ${NAME:-default} reference;
+// the escape sequences of the default are interpreted as in the rest of the value.
+//
 // If the returned token is token.Illegal, the literal string is the offending character.
+// An invalid UTF-8 encoding or a byte order mark after the beginning of the input is reported as token.Illegal
+// in place of the token that contains it, and the rest of the input is ignored.
 func (s *Scanner) NextToken() token.Token {
+	tok := s.nextToken()
+	if s.invalid < 0 {
+		return tok
+	}
+
+	tok = token.Token{
+		Type:    token.Illegal,
+		Literal: s.input[s.invalid : s.invalid+s.invalidSize],
+		Offset:  s.invalid,
+		Length:  s.invalidSize,
+		Pos:     s.position(s.invalid),
+	}
+
+	// Stop at the invalid character: the following calls return token.EOF.
+	s.input = s.input[:s.invalid]
+	s.offset, s.peekOffset, s.ch = s.invalid, s.invalid, eof
+	s.pending = nil
+	s.invalid = -1
+
+	return tok
+}
+
+func (s *Scanner) nextToken() token.Token {
+	if len(s.pending) > 0 {
+		tok := s.pending[0]
+		s.pending = s.pending[1:]
+
+		return tok
+	}
+
 	switch s.ch {
 	case eof:
-		return token.New(token.EOF, s.offset)
+		return s.newToken(token.EOF, token.EOF.String(), s.offset)
 	case '\n':
-		return s.scanNewLine()
+		return s.scanRuneAs(token.NewLine, "\n")
 	case ' ', '\t', '\r', '\v', '\f':
-		defer s.next()
-		return token.NewWithLiteral(token.Space, string(s.ch), s.offset)
+		return s.scanRuneAs(token.Space, string(s.ch))
 	case '=':
-		defer s.next()
-		return token.New(token.Assign, s.offset)
+		return s.scanRuneAs(token.Assign, token.Assign.String())
 	case '#':
 		return s.scanComment()
 	case '"':
@@ -70,13 +135,15 @@ func (s *Scanner) NextToken() token.Token {
 	case '\'':
 		return s.scanQuotedValue(token.RawValue, s.ch)
 	default:
-		switch prev := s.prev(); prev {
-		case '\n', bom:
+		switch prev := s.prev(); {
+		case prev == '\n' || prev == bom:
 			if isValidIdentifier(s.ch) {
 				return s.scanIdentifier()
 			}
-		case '=':
+		case prev == '=':
 			return s.scanUnquotedValue()
+		case s.ch == '<' && s.peek() == '<' && isValidIdentifier(prev):
+			return s.scanHeredoc()
 		}
 		return s.scanIllegalRune()
 	}
@@ -86,12 +153,11 @@ func (s *Scanner) NextToken() token.Token {
 // Methods that scan a specific token kind.
 // ========================================================================
 
-func (s *Scanner) scanNewLine() token.Token {
-	for isNewLine(s.ch) {
-		s.next()
-	}
+func (s *Scanner) scanRuneAs(tType token.Type, literal string) token.Token {
+	start := s.offset
+	s.next()
 
-	return token.NewWithLiteral(token.NewLine, "\n", s.offset)
+	return s.newToken(tType, literal, start)
 }
 
 func (s *Scanner) scanIdentifier() token.Token {
@@ -103,7 +169,7 @@ func (s *Scanner) scanIdentifier() token.Token {
 
 	literal := s.input[start:s.offset]
 
-	return token.NewWithLiteral(token.Identifier, literal, s.offset)
+	return s.newToken(token.Identifier, literal, start)
 }
 
 func (s *Scanner) scanComment() token.Token {
@@ -115,58 +181,333 @@ func (s *Scanner) scanComment() token.Token {
 
 	lit := s.input[start:s.offset]
 
-	return token.NewWithLiteral(token.Comment, lit, s.offset)
+	return s.newToken(token.Comment, lit, start)
 }
 
 func (s *Scanner) scanIllegalRune() token.Token {
+	start := s.offset
 	literal := string(s.ch)
-	offset := s.offset
 	s.next()
 
-	return token.NewWithLiteral(token.Illegal, literal, offset)
+	return s.newToken(token.Illegal, literal, start)
 }
 
 func (s *Scanner) scanUnquotedValue() token.Token {
+	return s.scanValue(token.Value, 0)
+}
+
+func (s *Scanner) scanQuotedValue(tType token.Type, quote rune) token.Token {
+	return s.scanValue(tType, quote)
+}
+
+// scanValue scans a value up to the closing quote, or up to the end of the line if the quote is 0.
+//
+// Line continuations, command substitutions and variable references are recognized in token.Value only.
+// A value that contains substitutions is split: the text before the first substitution is returned,
+// token.Command or token.Variable and token.Value for the text after it are queued for every substitution.
+func (s *Scanner) scanValue(tType token.Type, quote rune) token.Token {
+	var (
+		tokens []token.Token
+		lit    strings.Builder
+	)
+
+	start := s.offset
+
+	if quote != 0 {
+		s.next() // opening quote
+	}
+
+	segment := s.offset
+
+	for {
+		switch {
+		case tType == token.Value && s.ch == '\\' && s.isContinuation():
+			lit.WriteString(escape(s.input[segment:s.offset]))
+			s.scanContinuation()
+			segment = s.offset
+
+			continue
+		case tType == token.Value && s.ch == '$' && s.commandEnd() > 0:
+			lit.WriteString(escape(s.input[segment:s.offset]))
+			tokens = append(tokens, s.newToken(token.Value, lit.String(), start), s.scanCommand())
+			lit.Reset()
+			start, segment = s.offset, s.offset
+
+			continue
+		case tType == token.Value && s.ch == '$' && s.referenceEnd(quote) > 0:
+			lit.WriteString(escape(s.input[segment:s.offset]))
+			tokens = append(tokens, s.newToken(token.Value, lit.String(), start), s.scanReference(quote))
+			lit.Reset()
+			start, segment = s.offset, s.offset
+
+			continue
+		case isEOF(s.ch) || isNewLine(s.ch):
+			if quote != 0 {
+				// TODO (titusjaka): return human-readable error instead
+				tType = token.Illegal
+			}
+		case s.ch != quote:
+			s.next()
+			continue
+		}
+
+		break
+	}
+
+	value := s.input[segment:s.offset]
+
+	if tType == token.Value {
+		value = escape(value)
+	}
+
+	lit.WriteString(value)
+
+	if quote != 0 && s.ch == quote {
+		s.next()
+	}
+
+	tokens = append(tokens, s.newToken(tType, lit.String(), start))
+
+	for i := range tokens {
+		tokens[i].Quoted = quote != 0
+	}
+
+	s.pending = append(s.pending, tokens[1:]...)
+
+	return tokens[0]
+}
+
+// scanCommand scans a command substitution: "$(" followed by a command and the matching ")".
+// The command must be terminated, see commandEnd.
+func (s *Scanner) scanCommand() token.Token {
 	start := s.offset
+	end := s.commandEnd()
 
-	for !isEOF(s.ch) && !isNewLine(s.ch) {
+	for s.offset < end {
 		s.next()
 	}
 
-	lit := escape(s.input[start:s.offset])
+	s.next() // closing parenthesis
 
-	return token.NewWithLiteral(token.Value, lit, s.offset)
+	return s.newToken(token.Command, s.input[start+2:end], start)
 }
 
-func (s *Scanner) scanQuotedValue(tType token.Type, quote rune) token.Token {
-	// opening quote already consumed
-	s.next()
+// commandEnd returns the offset of the parenthesis that closes a command substitution started at the current
+// character. Nested parentheses and quoted strings inside the command are skipped. If the current character
+// does not start a command substitution, or it is not closed within the line, commandEnd returns 0.
+func (s *Scanner) commandEnd() int {
+	if !strings.HasPrefix(s.input[s.offset:], "$(") {
+		return 0
+	}
+
+	var quote byte
+
+	depth := 0
+
+	for i := s.offset + 1; i < len(s.input) && s.input[i] != '\n'; i++ {
+		switch c := s.input[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return 0
+}
+
+// scanReference scans a variable reference: "${" followed by a name, an optional ":-default", and "}".
+// The reference must be terminated, see referenceEnd.
+func (s *Scanner) scanReference(quote rune) token.Token {
 	start := s.offset
+	end := s.referenceEnd(quote)
 
-	for {
-		if isEOF(s.ch) || isNewLine(s.ch) {
-			// TODO (titusjaka): return human-readable error instead
-			tType = token.Illegal
+	for s.offset < end {
+		s.next()
+	}
+
+	s.next() // closing brace
+
+	return s.newToken(token.Variable, escape(s.input[start+2:end]), start)
+}
+
+// referenceEnd returns the offset of the brace that closes a variable reference started at the current character.
+// The default value of the reference cannot contain "}", the closing quote of the value or a line break.
+// If the current character does not start a valid reference, referenceEnd returns 0.
+func (s *Scanner) referenceEnd(quote rune) int {
+	if !strings.HasPrefix(s.input[s.offset:], "${") {
+		return 0
+	}
+
+	i := s.offset + 2
+	for i < len(s.input) {
+		r, size := utf8.DecodeRuneInString(s.input[i:])
+		if !isLetter(r) && !isDigit(r) && !isSymbol(r) {
 			break
 		}
-		if s.ch == quote {
-			break
+
+		i += size
+	}
+
+	if i == s.offset+2 {
+		return 0 // no name
+	}
+
+	if strings.HasPrefix(s.input[i:], ":-") {
+		i += 2
+
+		for i < len(s.input) && s.input[i] != '}' && s.input[i] != '\n' && (quote == 0 || rune(s.input[i]) != quote) {
+			i++
 		}
+	}
+
+	if i < len(s.input) && s.input[i] == '}' {
+		return i
+	}
+
+	return 0
+}
+
+// scanHeredoc scans a multi-line value introduced by "<<DELIMITER" (or "<<~DELIMITER") after the variable name.
+// The value consists of the following lines up to a line that equals the delimiter. In the "<<~" form
+// the closing delimiter may be indented, and the common indentation of the lines is stripped.
+//
+// The header is returned as token.Heredoc, the value is queued as token.RawValue.
+func (s *Scanner) scanHeredoc() token.Token {
+	start := s.offset
+
+	s.next() // the first '<'
+	s.next() // the second '<'
+
+	indented := s.ch == '~'
+	if indented {
 		s.next()
 	}
 
-	offset := s.offset
-	lit := s.input[start:offset]
+	delimStart := s.offset
+	for isLetter(s.ch) || isDigit(s.ch) || s.ch == '_' {
+		s.next()
+	}
 
-	if tType == token.Value {
-		lit = escape(lit)
+	delimiter := s.input[delimStart:s.offset]
+	header := s.newToken(token.Heredoc, s.input[start:s.offset], start)
+
+	lineBreak := s.lineBreakAt(s.offset)
+	if delimiter == "" || lineBreak == 0 {
+		header.Type = token.Illegal
+		return header
 	}
 
-	if s.ch == quote {
+	for ; lineBreak > 0; lineBreak-- {
+		s.next()
+	}
+
+	s.pending = append(s.pending, s.scanHeredocLines(delimiter, indented))
+
+	return header
+}
+
+func (s *Scanner) scanHeredocLines(delimiter string, indented bool) token.Token {
+	start := s.offset
+
+	var lines []string
+
+	for {
+		if isEOF(s.ch) {
+			return s.newToken(token.Illegal, strings.Join(lines, "\n"), start)
+		}
+
+		lineStart := s.offset
+		for !isEOF(s.ch) && !isNewLine(s.ch) {
+			s.next()
+		}
+
+		line := strings.TrimSuffix(s.input[lineStart:s.offset], "\r")
+
+		closing := line
+		if indented {
+			closing = strings.TrimLeft(line, " \t")
+		}
+
+		if closing == delimiter {
+			break
+		}
+
+		lines = append(lines, line)
+
+		if isNewLine(s.ch) {
+			s.next()
+		}
+	}
+
+	if indented {
+		lines = dedent(lines)
+	}
+
+	return s.newToken(token.RawValue, strings.Join(lines, "\n"), start)
+}
+
+// isContinuation reports whether the current backslash is followed by a line break,
+// so the value goes on from the next line.
+func (s *Scanner) isContinuation() bool {
+	return s.lineBreakAt(s.peekOffset) > 0
+}
+
+// scanContinuation consumes a backslash and the line break after it.
+// Leading blanks of the next line are consumed as well in the TrimContinuationIndent mode.
+func (s *Scanner) scanContinuation() {
+	for n := s.lineBreakAt(s.peekOffset); n >= 0; n-- {
 		s.next()
 	}
 
-	return token.NewWithLiteral(tType, lit, offset)
+	if s.mode&TrimContinuationIndent != 0 {
+		for s.ch == ' ' || s.ch == '\t' {
+			s.next()
+		}
+	}
+}
+
+// lineBreakAt returns the length of the line break (\n or \r\n) starting at the offset,
+// or 0 if there is no line break.
+func (s *Scanner) lineBreakAt(offset int) int {
+	switch {
+	case strings.HasPrefix(s.input[offset:], "\n"):
+		return 1
+	case strings.HasPrefix(s.input[offset:], "\r\n"):
+		return 2
+	default:
+		return 0
+	}
+}
+
+// newToken returns a token that was scanned from the source starting at the given offset up to the current one.
+func (s *Scanner) newToken(tType token.Type, literal string, start int) token.Token {
+	return token.Token{
+		Type:    tType,
+		Literal: literal,
+		Offset:  start,
+		Length:  s.offset - start,
+		Pos:     s.position(start),
+	}
+}
+
+// position returns the line and the column of the offset. The offset must be already scanned.
+func (s *Scanner) position(offset int) token.Position {
+	i := sort.Search(len(s.lines), func(i int) bool { return s.lines[i] > offset }) - 1
+
+	return token.Position{
+		Line:   i + 1,
+		Column: offset - s.lines[i] + 1,
+	}
 }
 
 // ========================================================================
@@ -176,6 +517,10 @@ func (s *Scanner) scanQuotedValue(tType token.Type, quote rune) token.Token {
 // Read the next Unicode char into s.ch.
 // s.ch < 0 means end-of-file.
 func (s *Scanner) next() {
+	if isNewLine(s.ch) {
+		s.lines = append(s.lines, s.peekOffset)
+	}
+
 	s.prevOffset = s.offset
 
 	if s.peekOffset < len(s.input) {
@@ -194,6 +539,15 @@ func (s *Scanner) next() {
 	}
 }
 
+// peek returns the byte after the current character without advancing the scanner, or 0 at the end of the input.
+func (s *Scanner) peek() byte {
+	if s.peekOffset < len(s.input) {
+		return s.input[s.peekOffset]
+	}
+
+	return 0
+}
+
 func (s *Scanner) prev() rune {
 	switch {
 	case s.prevOffset < 0:
@@ -215,15 +569,28 @@ func (s *Scanner) scanRune(offset int) (r rune, width int) {
 	case r >= utf8.RuneSelf:
 		// not ASCII
 		r, width = utf8.DecodeRune([]byte(s.input[offset:]))
-		if r == utf8.RuneError && width == 1 {
-			panic("illegal UTF-8 encoding on position " + strconv.Itoa(offset))
-		} else if r == bom && s.offset > 0 {
-			panic("illegal byte order mark on position " + strconv.Itoa(offset))
+		if (r == utf8.RuneError && width == 1 || r == bom && offset > 0) && s.invalid < 0 {
+			s.invalid, s.invalidSize = offset, width
 		}
 	}
 	return r, width
 }
 
+// IsIdentifier reports whether the name is a valid variable name.
+func IsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if !isValidIdentifier(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ========================================================================
 // Auxiliary methods that check if the rune is one of the specific kind.
 // ========================================================================
@@ -264,3 +631,37 @@ func lower(r rune) rune { return ('a' - 'A') | r } // returns lower-case r if r
 func escape(s string) string {
 	return escaper.Replace(s)
 }
+
+// dedent removes the longest common prefix of spaces and tabs from the lines. Blank lines are ignored while
+// computing the prefix, and they become empty.
+func dedent(lines []string) []string {
+	prefix := ""
+	found := false
+
+	for _, line := range lines {
+		if strings.TrimLeft(line, " \t") == "" {
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		switch {
+		case !found:
+			prefix, found = indent, true
+		default:
+			for !strings.HasPrefix(indent, prefix) {
+				prefix = prefix[:len(prefix)-1]
+			}
+		}
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.TrimPrefix(line, prefix)
+		if strings.TrimLeft(result[i], " \t") == "" {
+			result[i] = ""
+		}
+	}
+
+	return result
+}
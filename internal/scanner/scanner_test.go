@@ -36,6 +36,12 @@ func TestScanner_NextToken_Trivial(t *testing.T) {
 			expectedTokenType: token.Assign,
 			expectedLiteral:   token.Assign.String(),
 		},
+		{
+			name:              "BOM and then identifier",
+			input:             "\uFEFFFOO=bar",
+			expectedTokenType: token.Identifier,
+			expectedLiteral:   "FOO",
+		},
 		{
 			name:              "new lines",
 			input:             "\n\n",
@@ -350,6 +356,26 @@ func TestScanner_NextToken(t *testing.T) {
 				{Type: token.EOF, Literal: token.EOF.String()},
 			},
 		},
+		{
+			name:  "byte order mark after the beginning",
+			input: "x=y\uFEFF\nz=1\n",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "x"},
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Illegal, Literal: "\uFEFF"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "invalid UTF-8 encoding",
+			input: "x=\"y\xffz\"\n",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "x"},
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Illegal, Literal: "\xff"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
 		{
 			name: "naked value",
 			input: `x=yxc
@@ -389,3 +415,454 @@ func TestScanner_NextToken(t *testing.T) {
 		})
 	}
 }
+
+func TestScanner_NextToken_Continuation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		input             string
+		mode              scanner.Mode
+		expectedTokenType token.Type
+		expectedLiteral   string
+	}{
+		{
+			name:              "naked value",
+			input:             "=-Xms512m \\\n-Xmx2g",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m -Xmx2g",
+		},
+		{
+			name:              "naked value with CRLF",
+			input:             "=-Xms512m \\\r\n-Xmx2g",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m -Xmx2g",
+		},
+		{
+			name:              "naked value keeps indentation",
+			input:             "=-Xms512m \\\n    -Xmx2g",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m     -Xmx2g",
+		},
+		{
+			name:              "naked value trims indentation",
+			input:             "=-Xms512m \\\n  \t  -Xmx2g",
+			mode:              scanner.TrimContinuationIndent,
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m -Xmx2g",
+		},
+		{
+			name:              "naked value with several continuations",
+			input:             "=a\\\nb\\\nc",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "abc",
+		},
+		{
+			name:              "naked value ends with continuation",
+			input:             "=a\\\n",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "a",
+		},
+		{
+			name:              "escape sequences are not joined",
+			input:             "=a\\\\\nnb",
+			expectedTokenType: token.Value,
+			expectedLiteral:   `a\nb`,
+		},
+		{
+			name:              "backslash in the middle of the line",
+			input:             `=a\b`,
+			expectedTokenType: token.Value,
+			expectedLiteral:   `a\b`,
+		},
+		{
+			name:              "double quoted value",
+			input:             "=\"-Xms512m \\\n-Xmx2g\\t\"",
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m -Xmx2g\t",
+		},
+		{
+			name:              "double quoted value trims indentation",
+			input:             "=\"-Xms512m \\\n    -Xmx2g\"",
+			mode:              scanner.TrimContinuationIndent,
+			expectedTokenType: token.Value,
+			expectedLiteral:   "-Xms512m -Xmx2g",
+		},
+		{
+			name:              "single quoted value does not support continuation",
+			input:             "='-Xms512m \\\n-Xmx2g'",
+			expectedTokenType: token.Illegal,
+			expectedLiteral:   `-Xms512m \`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := scanner.NewWithMode(tt.input, tt.mode)
+			assign := sc.NextToken()
+			assert.Equal(t, token.Assign, assign.Type)
+
+			actual := sc.NextToken()
+			assert.Equal(t, tt.expectedTokenType, actual.Type)
+			assert.Equal(t, tt.expectedLiteral, actual.Literal)
+		})
+	}
+}
+
+func TestScanner_NextToken_Position(t *testing.T) {
+	t.Parallel()
+
+	input := "# comment\nJVM_OPTS=\"-Xms512m \\\n  -Xmx2g\"\nLOG_LEVEL=info \\\n  debug\n\nX"
+
+	expected := []token.Token{
+		{Type: token.Comment, Offset: 0, Length: 9, Pos: token.Position{Line: 1, Column: 1}},
+		{Type: token.NewLine, Offset: 9, Length: 1, Pos: token.Position{Line: 1, Column: 10}},
+		{Type: token.Identifier, Offset: 10, Length: 8, Pos: token.Position{Line: 2, Column: 1}},
+		{Type: token.Assign, Offset: 18, Length: 1, Pos: token.Position{Line: 2, Column: 9}},
+		{Type: token.Value, Offset: 19, Length: 21, Pos: token.Position{Line: 2, Column: 10}},
+		{Type: token.NewLine, Offset: 40, Length: 1, Pos: token.Position{Line: 3, Column: 10}},
+		{Type: token.Identifier, Offset: 41, Length: 9, Pos: token.Position{Line: 4, Column: 1}},
+		{Type: token.Assign, Offset: 50, Length: 1, Pos: token.Position{Line: 4, Column: 10}},
+		{Type: token.Value, Offset: 51, Length: 14, Pos: token.Position{Line: 4, Column: 11}},
+		{Type: token.NewLine, Offset: 65, Length: 1, Pos: token.Position{Line: 5, Column: 8}},
+		{Type: token.NewLine, Offset: 66, Length: 1, Pos: token.Position{Line: 6, Column: 1}},
+		{Type: token.Identifier, Offset: 67, Length: 1, Pos: token.Position{Line: 7, Column: 1}},
+		{Type: token.EOF, Offset: 68, Length: 0, Pos: token.Position{Line: 7, Column: 2}},
+	}
+
+	sc := scanner.New(input)
+
+	for _, e := range expected {
+		actual := sc.NextToken()
+
+		assert.Equal(t, e.Type, actual.Type)
+		assert.Equal(t, e.Offset, actual.Offset, "offset of %s", e.Type)
+		assert.Equal(t, e.Length, actual.Length, "length of %s", e.Type)
+		assert.Equal(t, e.Pos, actual.Pos, "position of %s", e.Type)
+	}
+}
+
+func TestScanner_NextToken_Heredoc(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "heredoc",
+			input: "KEY<<EOF\n{\n  \"type\": \"service_account\"\n}\nEOF\nNEXT=1",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<EOF"},
+				{Type: token.RawValue, Literal: "{\n  \"type\": \"service_account\"\n}"},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.Identifier, Literal: "NEXT"},
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "1"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "lines are raw",
+			input: "KEY<<END\n'single' \"double\" \\n $(cmd)\nEND",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<END"},
+				{Type: token.RawValue, Literal: `'single' "double" \n $(cmd)`},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "empty heredoc",
+			input: "KEY<<EOF\nEOF\n",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<EOF"},
+				{Type: token.RawValue, Literal: ""},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "KEY<<EOF\r\nline 1\r\nline 2\r\nEOF\r\n",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<EOF"},
+				{Type: token.RawValue, Literal: "line 1\nline 2"},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "indented heredoc",
+			input: "KEY<<~EOF\n    line 1\n\n      line 2\n  EOF",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<~EOF"},
+				{Type: token.RawValue, Literal: "line 1\n\n  line 2"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "indented delimiter does not close plain heredoc",
+			input: "KEY<<EOF\n  EOF\nEOF",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<EOF"},
+				{Type: token.RawValue, Literal: "  EOF"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "unterminated heredoc",
+			input: "KEY<<EOF\nline 1\n",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Heredoc, Literal: "<<EOF"},
+				{Type: token.Illegal, Literal: "line 1"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "heredoc without delimiter",
+			input: "KEY<<\nEOF",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Illegal, Literal: "<<"},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.Identifier, Literal: "EOF"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "text after delimiter",
+			input: "KEY<<EOF text",
+			expected: []token.Token{
+				{Type: token.Identifier, Literal: "KEY"},
+				{Type: token.Illegal, Literal: "<<EOF"},
+				{Type: token.Space, Literal: " "},
+				{Type: token.Illegal, Literal: "t"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := scanner.New(tt.input)
+
+			for _, expected := range tt.expected {
+				actual := sc.NextToken()
+
+				assert.Equal(t, expected.Type, actual.Type)
+				assert.Equal(t, expected.Literal, actual.Literal)
+			}
+		})
+	}
+}
+
+func TestIsIdentifier(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, scanner.IsIdentifier("valid-name.with_special,symbols"))
+	assert.True(t, scanner.IsIdentifier("КИРИЛЛИЦА"))
+	assert.False(t, scanner.IsIdentifier(""))
+	assert.False(t, scanner.IsIdentifier("this/name/contains/slashes"))
+	assert.False(t, scanner.IsIdentifier("NAME WITH SPACE"))
+}
+
+func TestScanner_NextToken_Variable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "naked value",
+			input: "=http://${HOST}:${PORT:-8080}/",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "http://"},
+				{Type: token.Variable, Literal: "HOST"},
+				{Type: token.Value, Literal: ":"},
+				{Type: token.Variable, Literal: "PORT:-8080"},
+				{Type: token.Value, Literal: "/"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "double quoted value",
+			input: "=\"${GREETING:-hello, world} $(whoami)\"\n",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: ""},
+				{Type: token.Variable, Literal: "GREETING:-hello, world"},
+				{Type: token.Value, Literal: " "},
+				{Type: token.Command, Literal: "whoami"},
+				{Type: token.Value, Literal: ""},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "escaped default",
+			input: "=\"${TEXT:-a\\nb}\"\n",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: ""},
+				{Type: token.Variable, Literal: "TEXT:-a\nb"},
+				{Type: token.Value, Literal: ""},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "single quoted value",
+			input: "='${HOST}'",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.RawValue, Literal: "${HOST}"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "invalid references are a text",
+			input: "=${} ${HOST ${A/B} ${HOST:-\n",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "${} ${HOST ${A/B} ${HOST:-"},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "default value stops at the closing quote",
+			input: "=\"${HOST:-\"}",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "${HOST:-"},
+				{Type: token.Illegal, Literal: "}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := scanner.New(tt.input)
+
+			for _, expected := range tt.expected {
+				actual := sc.NextToken()
+
+				assert.Equal(t, expected.Type, actual.Type)
+				assert.Equal(t, expected.Literal, actual.Literal)
+			}
+		})
+	}
+}
+
+func TestScanner_NextToken_Command(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "naked value",
+			input: "=sha-$(git rev-parse HEAD)-dirty",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "sha-"},
+				{Type: token.Command, Literal: "git rev-parse HEAD"},
+				{Type: token.Value, Literal: "-dirty"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "double quoted value",
+			input: "=\"$(date) $(echo \"(nested)\" $(whoami))\"\n",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: ""},
+				{Type: token.Command, Literal: "date"},
+				{Type: token.Value, Literal: " "},
+				{Type: token.Command, Literal: `echo "(nested)" $(whoami)`},
+				{Type: token.Value, Literal: ""},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "single quoted value",
+			input: "='$(date)'",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.RawValue, Literal: "$(date)"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "not terminated substitution is a text",
+			input: "=$(date\n",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "$(date"},
+				{Type: token.NewLine, Literal: "\n"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "dollar sign without parenthesis",
+			input: "=$HOME $",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: "$HOME $"},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+		{
+			name:  "not paired quotes after substitution",
+			input: "=\"$(date)",
+			expected: []token.Token{
+				{Type: token.Assign, Literal: token.Assign.String()},
+				{Type: token.Value, Literal: ""},
+				{Type: token.Command, Literal: "date"},
+				{Type: token.Illegal, Literal: ""},
+				{Type: token.EOF, Literal: token.EOF.String()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := scanner.New(tt.input)
+
+			for _, expected := range tt.expected {
+				actual := sc.NextToken()
+
+				assert.Equal(t, expected.Type, actual.Type)
+				assert.Equal(t, expected.Literal, actual.Literal)
+			}
+		})
+	}
+}
@@ -21,6 +21,9 @@ const (
 	Identifier // Name of the variable
 	Value      // Value is an interpreted value of the variable, if it contains special characters, they will be escaped
 	RawValue   // RawValue is used as-is. Special characters are not escaped.
+	Heredoc    // Heredoc introduces a multi-line value: <<DELIMITER or <<~DELIMITER, the RawValue with the lines follows
+	Command    // Command is a command of the $(command) substitution inside a value
+	Variable   // Variable is a reference to a variable inside a value: ${NAME} or ${NAME:-default}
 	Space      // All whitespace symbols except \n (new line)
 	NewLine    // A new line symbol (\n)
 )
@@ -36,6 +39,9 @@ var tokens = [...]string{
 	Identifier: "IDENTIFIER",
 	Value:      "VALUE",
 	RawValue:   "RAW_VALUE",
+	Heredoc:    "HEREDOC",
+	Command:    "COMMAND",
+	Variable:   "VARIABLE",
 	Space:      "SPACE",
 	NewLine:    "NEW_LINE",
 }
@@ -55,23 +61,26 @@ func (t Type) String() string {
 	return s
 }
 
+// Position describes a location in the source. Line and Column are 1-based, the column is counted in bytes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// String returns the position in the "line:column" form.
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// Token is a lexical token of the .env file.
+//
+// Offset and Length describe the span of the source text the token was scanned from. The span may differ from
+// Literal: quotes, escape sequences and line continuations are not a part of the literal.
 type Token struct {
 	Type    Type
 	Literal string
 	Offset  int
 	Length  int
-}
-
-func New(t Type, offset int) Token {
-	return NewWithLiteral(t, t.String(), offset)
-}
-
-func NewWithLiteral(t Type, literal string, offset int) Token {
-	length := len(literal)
-	return Token{
-		Type:    t,
-		Literal: literal,
-		Offset:  offset - length,
-		Length:  length,
-	}
+	Pos     Position // position of the first character of the token
+	Quoted  bool     // the value is enclosed in quotes
 }
@@ -0,0 +1,94 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/lint"
+)
+
+var findings = []lint.Finding{
+	{Rule: lint.RuleLowercaseKey, Pos: godenv.Position{Filename: "config/.env", Line: 1, Column: 1}, Message: "key foo contains lowercase letters"},
+	{Rule: lint.RuleEmptyValue, Pos: godenv.Position{Filename: "config/.env", Line: 2, Column: 1}, Message: "the value of BAR is empty"},
+}
+
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, lint.WriteText(&buf, findings))
+	assert.Equal(t, `config/.env:1:1: key foo contains lowercase letters (lowercase-key)
+config/.env:2:1: the value of BAR is empty (empty-value)
+`, buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("findings", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, lint.WriteJSON(&buf, findings[:1]))
+		assert.JSONEq(t, `[{
+			"rule": "lowercase-key",
+			"position": {"filename": "config/.env", "line": 1, "column": 1},
+			"message": "key foo contains lowercase letters"
+		}]`, buf.String())
+	})
+
+	t.Run("no findings", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, lint.WriteJSON(&buf, nil))
+		assert.JSONEq(t, `[]`, buf.String())
+	})
+}
+
+func TestWriteSARIF(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	rules := []*lint.Rule{lint.LookupRule(lint.RuleLowercaseKey)}
+	require.NoError(t, lint.WriteSARIF(&buf, findings[:1], rules))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []json.RawMessage `json:"results"`
+		} `json:"runs"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Equal(t, "godenv", log.Runs[0].Tool.Driver.Name)
+	require.Len(t, log.Runs[0].Tool.Driver.Rules, 1)
+	assert.Equal(t, lint.RuleLowercaseKey, log.Runs[0].Tool.Driver.Rules[0].ID)
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.JSONEq(t, `{
+		"ruleId": "lowercase-key",
+		"level": "error",
+		"message": {"text": "key foo contains lowercase letters"},
+		"locations": [{
+			"physicalLocation": {
+				"artifactLocation": {"uri": "config/.env"},
+				"region": {"startLine": 1, "startColumn": 1}
+			}
+		}]
+	}`, string(log.Runs[0].Results[0]))
+}
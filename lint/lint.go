@@ -0,0 +1,167 @@
+// Package lint finds problems in the .env files: the lines that cannot be parsed and the constructs
+// that are valid, but likely to be a mistake, like duplicate keys or unquoted values with a "#".
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/scanner"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// Finding is a problem found by a rule.
+type Finding struct {
+	Rule    string          `json:"rule"`
+	Pos     godenv.Position `json:"position"`
+	Message string          `json:"message"`
+}
+
+// String returns the finding in the "file:line:column: message (rule)" form.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Pos, f.Message, f.Rule)
+}
+
+// Option configures the Linter.
+type Option func(*options)
+
+type options struct {
+	enable  []string
+	disable []string
+}
+
+// Enable makes the Linter check only the given rules. By default, all rules are checked.
+func Enable(rules ...string) Option {
+	return func(o *options) {
+		o.enable = append(o.enable, rules...)
+	}
+}
+
+// Disable turns the given rules off.
+func Disable(rules ...string) Option {
+	return func(o *options) {
+		o.disable = append(o.disable, rules...)
+	}
+}
+
+// Linter checks the .env files with a set of rules.
+type Linter struct {
+	rules []*Rule
+}
+
+// New returns a Linter that checks the rules selected by the options.
+// It returns an error if an option refers to an unknown rule.
+func New(opts ...Option) (*Linter, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	for _, name := range append(append([]string(nil), o.enable...), o.disable...) {
+		if LookupRule(name) == nil {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	l := &Linter{}
+
+	for _, rule := range Rules() {
+		if len(o.enable) > 0 && !contains(o.enable, rule.Name) {
+			continue
+		}
+
+		if contains(o.disable, rule.Name) {
+			continue
+		}
+
+		l.rules = append(l.rules, rule)
+	}
+
+	return l, nil
+}
+
+// Rules returns the rules checked by the Linter.
+func (l *Linter) Rules() []*Rule {
+	return l.rules
+}
+
+// Lint checks the content of the file and returns the findings sorted by their position.
+// The filename is used in the positions of the findings only.
+func (l *Linter) Lint(filename string, src []byte) []Finding {
+	f := &file{
+		src: string(src),
+	}
+
+	f.parseErr = f.parse()
+
+	var findings []Finding
+
+	for _, rule := range l.rules {
+		if f.parseErr != nil && !rule.syntactic {
+			continue
+		}
+
+		rule.check(f, func(pos token.Position, format string, args ...interface{}) {
+			findings = append(findings, Finding{
+				Rule:    rule.Name,
+				Pos:     godenv.Position{Filename: filename, Line: pos.Line, Column: pos.Column},
+				Message: fmt.Sprintf(format, args...),
+			})
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return findings
+}
+
+// file is the file being checked.
+type file struct {
+	src        string
+	statements []ast.Statement
+	parseErr   *parser.Error
+}
+
+func (f *file) parse() *parser.Error {
+	if !utf8.ValidString(f.src) {
+		return &parser.Error{Pos: token.Position{Line: 1, Column: 1}, Msg: "illegal UTF-8 encoding", SafeMsg: "illegal UTF-8 encoding"}
+	}
+
+	statement, err := parser.New(scanner.New(f.src)).Parse()
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			return syntaxErr
+		}
+
+		return &parser.Error{Pos: token.Position{Line: 1, Column: 1}, Msg: err.Error(), SafeMsg: err.Error()}
+	}
+
+	if file, ok := statement.(*ast.FileStatement); ok {
+		f.statements = file.Statements
+	}
+
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/lint"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []lint.Finding
+	}{
+		{
+			name:  "clean file",
+			input: "# Database\nDB_HOST=localhost\nDB_PORT=5432\n\n# HTTP\nHTTP_ADDR=':8080 # not a comment'\nHTTP_PREFIX=\"\"\n",
+		},
+		{
+			name:  "parse error",
+			input: "FOO=bar\nBAR= baz\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleParse, Pos: godenv.Position{Filename: ".env", Line: 2, Column: 5}, Message: "unexpected token: SPACE( )"},
+			},
+		},
+		{
+			name:  "invalid UTF-8",
+			input: "FOO=\xff\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleParse, Pos: godenv.Position{Filename: ".env", Line: 1, Column: 1}, Message: "illegal UTF-8 encoding"},
+			},
+		},
+		{
+			name:  "byte order mark after the beginning",
+			input: "FOO=bar\uFEFF\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleParse, Pos: godenv.Position{Filename: ".env", Line: 1, Column: 8}, Message: "unexpected token: Illegal"},
+			},
+		},
+		{
+			name:  "byte order mark",
+			input: "\uFEFFFOO=bar\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleBOM, Pos: godenv.Position{Filename: ".env", Line: 1, Column: 1}, Message: "the file starts with a byte order mark"},
+			},
+		},
+		{
+			name:  "duplicate key",
+			input: "BAR=1\nFOO=2\n\nFOO<<EOF\n3\nEOF\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleDuplicateKey, Pos: godenv.Position{Filename: ".env", Line: 4, Column: 1}, Message: "duplicate key FOO, first assigned at 2:1"},
+			},
+		},
+		{
+			name:  "lowercase key",
+			input: "Foo=bar\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleLowercaseKey, Pos: godenv.Position{Filename: ".env", Line: 1, Column: 1}, Message: "key Foo contains lowercase letters"},
+			},
+		},
+		{
+			name:  "trailing whitespace",
+			input: "BAR=\"bar \"\nFOO=bar \t\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleTrailingWhitespace, Pos: godenv.Position{Filename: ".env", Line: 2, Column: 1}, Message: "the value of FOO ends with whitespace"},
+			},
+		},
+		{
+			name:  "unquoted hash",
+			input: "BAR=\"bar # baz\"\nFOO=bar # comment\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleUnquotedHash, Pos: godenv.Position{Filename: ".env", Line: 2, Column: 1}, Message: `the unquoted value of FOO contains "#", that is not a comment`},
+			},
+		},
+		{
+			name:  "empty value",
+			input: "BAR=''\nBAZ\nFOO=\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleEmptyValue, Pos: godenv.Position{Filename: ".env", Line: 2, Column: 1}, Message: "the value of BAZ is empty"},
+				{Rule: lint.RuleEmptyValue, Pos: godenv.Position{Filename: ".env", Line: 3, Column: 1}, Message: "the value of FOO is empty"},
+			},
+		},
+		{
+			name:  "unsorted keys",
+			input: "B=1\nC=2\nA=3\n\nZ=4\n# comment\nY=5\nX=6\n",
+			expected: []lint.Finding{
+				{Rule: lint.RuleUnsortedKeys, Pos: godenv.Position{Filename: ".env", Line: 3, Column: 1}, Message: "key A is not sorted, it must go before C"},
+				{Rule: lint.RuleUnsortedKeys, Pos: godenv.Position{Filename: ".env", Line: 8, Column: 1}, Message: "key X is not sorted, it must go before Y"},
+			},
+		},
+	}
+
+	l, err := lint.New()
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, l.Lint(".env", []byte(tt.input)))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("foo=bar # comment\n")
+
+	t.Run("enable", func(t *testing.T) {
+		l, err := lint.New(lint.Enable(lint.RuleLowercaseKey))
+		require.NoError(t, err)
+
+		findings := l.Lint("", input)
+		require.Len(t, findings, 1)
+		assert.Equal(t, lint.RuleLowercaseKey, findings[0].Rule)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		l, err := lint.New(lint.Disable(lint.RuleLowercaseKey))
+		require.NoError(t, err)
+
+		findings := l.Lint("", input)
+		require.Len(t, findings, 1)
+		assert.Equal(t, lint.RuleUnquotedHash, findings[0].Rule)
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		_, err := lint.New(lint.Disable("no-such-rule"))
+		require.EqualError(t, err, `unknown rule "no-such-rule"`)
+	})
+}
@@ -0,0 +1,199 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// The names of the rules.
+const (
+	RuleParse              = "parse"
+	RuleBOM                = "bom"
+	RuleDuplicateKey       = "duplicate-key"
+	RuleLowercaseKey       = "lowercase-key"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleUnquotedHash       = "unquoted-hash"
+	RuleEmptyValue         = "empty-value"
+	RuleUnsortedKeys       = "unsorted-keys"
+)
+
+// Rule is a check of the .env file.
+type Rule struct {
+	Name        string
+	Description string
+
+	syntactic bool // the rule checks the source and runs even if the file cannot be parsed
+	check     func(f *file, report reportFunc)
+}
+
+type reportFunc func(pos token.Position, format string, args ...interface{})
+
+// Rules returns all rules in the order they are checked.
+func Rules() []*Rule {
+	return []*Rule{
+		{
+			Name:        RuleParse,
+			Description: "The file must be parsed without errors.",
+			syntactic:   true,
+			check:       checkParse,
+		},
+		{
+			Name:        RuleBOM,
+			Description: "The file must not start with a byte order mark.",
+			syntactic:   true,
+			check:       checkBOM,
+		},
+		{
+			Name:        RuleDuplicateKey,
+			Description: "A key must be assigned once, the later assignments silently override the earlier ones.",
+			check:       checkDuplicateKey,
+		},
+		{
+			Name:        RuleLowercaseKey,
+			Description: "A key must not contain lowercase letters.",
+			check:       checkLowercaseKey,
+		},
+		{
+			Name:        RuleTrailingWhitespace,
+			Description: "An unquoted value must not end with whitespace, it is a part of the value.",
+			check:       checkTrailingWhitespace,
+		},
+		{
+			Name:        RuleUnquotedHash,
+			Description: `An unquoted value must not contain "#", it is a part of the value, not a comment.`,
+			check:       checkUnquotedHash,
+		},
+		{
+			Name:        RuleEmptyValue,
+			Description: `A value must not be empty, an intentionally empty value must be written as "".`,
+			check:       checkEmptyValue,
+		},
+		{
+			Name:        RuleUnsortedKeys,
+			Description: "The keys of a group must be sorted, the groups are separated by blank lines and comments.",
+			check:       checkUnsortedKeys,
+		},
+	}
+}
+
+// LookupRule returns the rule by its name, or nil if there is no such rule.
+func LookupRule(name string) *Rule {
+	for _, rule := range Rules() {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func checkParse(f *file, report reportFunc) {
+	if f.parseErr != nil {
+		report(f.parseErr.Pos, "%s", f.parseErr.SafeMsg)
+	}
+}
+
+func checkBOM(f *file, report reportFunc) {
+	if strings.HasPrefix(f.src, "\uFEFF") {
+		report(token.Position{Line: 1, Column: 1}, "the file starts with a byte order mark")
+	}
+}
+
+func checkDuplicateKey(f *file, report reportFunc) {
+	assigned := make(map[string]token.Position)
+
+	for _, stmt := range f.statements {
+		name, pos, ok := assignment(stmt)
+		if !ok {
+			continue
+		}
+
+		if first, ok := assigned[name]; ok {
+			report(pos, "duplicate key %s, first assigned at %s", name, first)
+			continue
+		}
+
+		assigned[name] = pos
+	}
+}
+
+func checkLowercaseKey(f *file, report reportFunc) {
+	for _, stmt := range f.statements {
+		if name, pos, ok := assignment(stmt); ok && strings.ToUpper(name) != name {
+			report(pos, "key %s contains lowercase letters", name)
+		}
+	}
+}
+
+func checkTrailingWhitespace(f *file, report reportFunc) {
+	for _, stmt := range f.statements {
+		assign, ok := stmt.(*ast.AssignStatement)
+		if !ok || assign.Quoted {
+			continue
+		}
+
+		if trimmed := strings.TrimRight(assign.Value, " \t"); trimmed != assign.Value {
+			report(assign.Pos, "the value of %s ends with whitespace", assign.Name)
+		}
+	}
+}
+
+func checkUnquotedHash(f *file, report reportFunc) {
+	for _, stmt := range f.statements {
+		assign, ok := stmt.(*ast.AssignStatement)
+		if !ok || assign.Quoted {
+			continue
+		}
+
+		if strings.Contains(assign.Value, "#") {
+			report(assign.Pos, `the unquoted value of %s contains "#", that is not a comment`, assign.Name)
+		}
+	}
+}
+
+func checkEmptyValue(f *file, report reportFunc) {
+	for _, stmt := range f.statements {
+		assign, ok := stmt.(*ast.AssignStatement)
+		if !ok || assign.Quoted {
+			continue
+		}
+
+		if assign.Value == "" {
+			report(assign.Pos, "the value of %s is empty", assign.Name)
+		}
+	}
+}
+
+func checkUnsortedKeys(f *file, report reportFunc) {
+	var prev string
+
+	for _, stmt := range f.statements {
+		name, pos, ok := assignment(stmt)
+		if !ok {
+			prev = "" // a blank line or a comment starts a new group
+			continue
+		}
+
+		if name < prev {
+			report(pos, "key %s is not sorted, it must go before %s", name, prev)
+		}
+
+		if name > prev {
+			prev = name
+		}
+	}
+}
+
+// assignment returns the name and the position of the assignment statement.
+func assignment(stmt ast.Statement) (string, token.Position, bool) {
+	switch stmt := stmt.(type) {
+	case *ast.AssignStatement:
+		return stmt.Name, stmt.Pos, true
+	case *ast.HeredocStatement:
+		return stmt.Name, stmt.Pos, true
+	default:
+		return "", token.Position{}, false
+	}
+}
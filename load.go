@@ -0,0 +1,333 @@
+package godenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/scanner"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// loader parses .env files and follows their include directives.
+type loader struct {
+	o        *options
+	vars     *Vars
+	files    []string          // files that are being loaded, the outermost first
+	loaded   map[string]uint64 // digests of the files that have been read, including the included ones
+	includes []Position        // include directives that led to the current file, the outermost first
+
+	references map[string][]Position // variables assigned references to resolve, see Resolver
+
+	parent *loader // the loader of the including file, if the file is included ahead, see loadIncludes
+}
+
+func newLoader(o *options) *loader {
+	return &loader{
+		o:          o,
+		vars:       newVars(o.redactPatterns),
+		references: make(map[string][]Position),
+		loaded:     make(map[string]uint64),
+	}
+}
+
+// loadFile reads the file and loads its content.
+func (l *loader) loadFile(name string) error {
+	input, err := l.readFile(name)
+	if err != nil {
+		return err
+	}
+
+	return l.load(name, input)
+}
+
+// load parses the content of the file and evaluates its statements in order.
+// The name is used to resolve the include directives, it is empty if the content is read from io.Reader.
+func (l *loader) load(name string, input []byte) error {
+	l.files = append(l.files, name)
+	defer func() { l.files = l.files[:len(l.files)-1] }()
+
+	statement, err := parser.New(scanner.NewWithMode(string(input), l.o.scanMode)).Parse()
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			msg := syntaxErr.SafeMsg
+			if l.o.debugErrors {
+				msg = syntaxErr.Msg
+			}
+
+			return l.errorAt(name, syntaxErr.Pos, errors.New(msg))
+		}
+
+		return err
+	}
+
+	file, ok := statement.(*ast.FileStatement)
+	if !ok {
+		return fmt.Errorf("unexpected statement: %T", statement)
+	}
+
+	// The included files and the variables evaluated in the order of the dependencies, if enabled.
+	var (
+		included map[ast.Statement]*loader
+		values   map[ast.Statement]Variable
+	)
+
+	if l.o.dependencyOrder {
+		if included, err = l.loadIncludes(name, file); err != nil {
+			return err
+		}
+
+		if values, err = l.evaluateInOrder(name, file, included); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range file.Statements {
+		if variable, ok := values[stmt]; ok {
+			l.setValue(variable)
+			continue
+		}
+
+		if child, ok := included[stmt]; ok {
+			l.merge(child)
+			continue
+		}
+
+		if err := l.loadStatement(name, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *loader) loadStatement(name string, stmt ast.Statement) error {
+	switch stmt := stmt.(type) {
+	case *ast.AssignStatement:
+		value, err := evaluate(stmt, l.o, l.lookup)
+		if err != nil {
+			return l.errorAt(name, stmt.Pos, err)
+		}
+
+		origin, pos := valueOrigin(stmt, l.o, l.lookup)
+
+		if value, err = decrypt(stmt.Name, value, l.o); err != nil {
+			return l.errorAt(name, stmt.Pos, err)
+		}
+
+		l.setValue(Variable{Name: stmt.Name, Value: value, Pos: position(name, pos), Origin: origin})
+	case *ast.HeredocStatement:
+		return l.set(name, stmt.Name, stmt.Value, stmt.Pos)
+	case *ast.IncludeStatement:
+		return l.include(name, stmt)
+	}
+
+	return nil
+}
+
+// lookup returns the value of the variable assigned earlier, or the value of the external lookup, if any.
+func (l *loader) lookup(name string) (string, bool) {
+	if value, ok := l.vars.Lookup(name); ok {
+		return value, true
+	}
+
+	if l.parent != nil {
+		return l.parent.lookup(name)
+	}
+
+	if l.o.lookup == nil {
+		return "", false
+	}
+
+	return l.o.lookup(name)
+}
+
+// set assigns the variable, decrypting the value if it is encrypted.
+// The references are remembered to be resolved once all the files are loaded.
+func (l *loader) set(from, name, value string, pos token.Position) error {
+	value, err := decrypt(name, value, l.o)
+	if err != nil {
+		return l.errorAt(from, pos, err)
+	}
+
+	l.setValue(Variable{Name: name, Value: value, Pos: position(from, pos)})
+
+	return nil
+}
+
+// setValue assigns the variable with the decrypted value, remembering the reference to resolve, if any.
+func (l *loader) setValue(variable Variable) {
+	if ref, _ := reference(variable.Value, l.o); ref != nil {
+		l.references[variable.Name] = l.includedFrom()
+	} else {
+		delete(l.references, variable.Name)
+	}
+
+	l.vars.set(variable)
+}
+
+// include loads the file included by the directive. The included variables override the variables
+// assigned before the directive, and they are overridden by the ones assigned after it.
+func (l *loader) include(from string, stmt *ast.IncludeStatement) error {
+	if from == "" && l.o.fsys == nil {
+		return l.errorAt(from, stmt.Pos, ErrIncludeNotAllowed)
+	}
+
+	name, err := l.resolve(from, stmt.Path)
+	if err != nil {
+		return l.errorAt(from, stmt.Pos, err)
+	}
+
+	if len(l.includes) >= l.o.maxIncludeDepth {
+		return l.errorAt(from, stmt.Pos, fmt.Errorf("%w: %d", ErrIncludeDepth, l.o.maxIncludeDepth))
+	}
+
+	for i, file := range l.files {
+		if l.identity(file) == l.identity(name) {
+			cycle := append(append([]string(nil), l.files[i:]...), name)
+			return l.errorAt(from, stmt.Pos, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(cycle, " -> ")))
+		}
+	}
+
+	input, err := l.readFile(name)
+	if err != nil {
+		return l.errorAt(from, stmt.Pos, err)
+	}
+
+	l.includes = append(l.includes, position(from, stmt.Pos))
+	defer func() { l.includes = l.includes[:len(l.includes)-1] }()
+
+	return l.load(name, input)
+}
+
+// loadIncludes loads the files included by the file ahead of its assignments, each by its own loader,
+// so the assignments evaluated in the order of the dependencies can reference the included variables.
+// The included files see the variables assigned before the file, but not the variables of the file.
+func (l *loader) loadIncludes(name string, file *ast.FileStatement) (map[ast.Statement]*loader, error) {
+	included := make(map[ast.Statement]*loader)
+
+	for _, stmt := range file.Statements {
+		if s, ok := stmt.(*ast.IncludeStatement); ok {
+			child := &loader{
+				o:          l.o,
+				vars:       newVars(l.o.redactPatterns),
+				files:      l.files,
+				loaded:     l.loaded,
+				includes:   l.includes,
+				references: make(map[string][]Position),
+				parent:     l,
+			}
+
+			if err := child.include(name, s); err != nil {
+				return nil, err
+			}
+
+			included[stmt] = child
+		}
+	}
+
+	return included, nil
+}
+
+// merge assigns the variables loaded by the loader of an included file, along with the values they override.
+func (l *loader) merge(child *loader) {
+	for _, key := range child.vars.keys {
+		for _, variable := range child.vars.history[key] {
+			l.vars.set(variable)
+		}
+
+		l.vars.set(child.vars.vars[key])
+
+		if includedFrom, ok := child.references[key]; ok {
+			l.references[key] = includedFrom
+		} else {
+			delete(l.references, key)
+		}
+	}
+}
+
+// resolve returns the name of the file included from the file by the path. A relative path is resolved
+// against the directory of the including file, or against the root of fs.FS, if the content is read
+// from io.Reader.
+func (l *loader) resolve(from, includePath string) (string, error) {
+	if l.o.fsys == nil {
+		if filepath.IsAbs(includePath) {
+			return includePath, nil
+		}
+
+		return filepath.Join(filepath.Dir(from), includePath), nil
+	}
+
+	name := path.Join(path.Dir(from), includePath)
+	if path.IsAbs(includePath) {
+		name = path.Clean(strings.TrimPrefix(includePath, "/"))
+	}
+
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("include path %q is outside of the file system", includePath)
+	}
+
+	return name, nil
+}
+
+func (l *loader) readFile(name string) ([]byte, error) {
+	input, err := readFile(l.o.fsys, name)
+	l.loaded[name] = digest(input, err)
+
+	return input, err
+}
+
+// readFile reads the file from fsys, or from the operating system if fsys is nil.
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys != nil {
+		return fs.ReadFile(fsys, name)
+	}
+
+	return os.ReadFile(name)
+}
+
+// identity returns the name that identifies the file while checking for include cycles.
+func (l *loader) identity(name string) string {
+	if l.o.fsys != nil || name == "" {
+		return name
+	}
+
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return name
+}
+
+func (l *loader) errorAt(name string, pos token.Position, err error) error {
+	return &Error{
+		Pos:          position(name, pos),
+		IncludedFrom: l.includedFrom(),
+		Err:          err,
+	}
+}
+
+// includedFrom returns the include directives that led to the current file, the innermost first.
+func (l *loader) includedFrom() []Position {
+	var includedFrom []Position
+	for i := len(l.includes) - 1; i >= 0; i-- {
+		includedFrom = append(includedFrom, l.includes[i])
+	}
+
+	return includedFrom
+}
+
+func position(name string, pos token.Position) Position {
+	return Position{
+		Filename: name,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	}
+}
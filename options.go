@@ -0,0 +1,200 @@
+package godenv
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+const defaultMaxIncludeDepth = 10
+
+// Option configures how the .env content is parsed.
+type Option func(*options)
+
+type options struct {
+	scanMode        scanner.Mode
+	commandRunner   CommandRunner
+	rejectCommands  bool
+	fsys            fs.FS
+	filename        string
+	maxIncludeDepth int
+	redactPatterns  []string
+	debugErrors     bool
+	decrypter       Decrypter
+	resolvers       map[string]*registeredResolver // by the lowercase scheme
+	expand          bool
+	dependencyOrder bool
+	lookup          func(string) (string, bool)
+	ctx             context.Context
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		maxIncludeDepth: defaultMaxIncludeDepth,
+		redactPatterns:  DefaultRedactPatterns(),
+		resolvers:       make(map[string]*registeredResolver),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// TrimContinuationIndent makes the parser drop leading spaces and tabs of a line
+// that continues a value after a trailing backslash.
+func TrimContinuationIndent() Option {
+	return func(o *options) {
+		o.scanMode |= scanner.TrimContinuationIndent
+	}
+}
+
+// WithCommandRunner enables $(command) substitutions: the commands are run by the runner,
+// and the substitutions are replaced with the output.
+func WithCommandRunner(runner CommandRunner) Option {
+	return func(o *options) {
+		o.commandRunner = runner
+	}
+}
+
+// RejectCommandSubstitution makes the parser return ErrCommandSubstitution for values that contain
+// $(command) substitutions, unless a CommandRunner is configured. By default such values are kept literal.
+func RejectCommandSubstitution() Option {
+	return func(o *options) {
+		o.rejectCommands = true
+	}
+}
+
+// WithLookup enables the expansion of the ${NAME} and ${NAME:-default} references in the unquoted and double-quoted
+// values. A reference is replaced with the value of the variable assigned earlier in the files, or, if there is
+// none, with the value returned by lookup, e.g. os.LookupEnv or the Lookup method of Source. A nil lookup
+// limits the expansion to the variables of the files, so the result does not depend on the environment.
+// A variable that is not set expands to an empty string.
+//
+// Without WithLookup, the references are kept as they are written.
+func WithLookup(lookup func(name string) (string, bool)) Option {
+	return func(o *options) {
+		o.expand = true
+		o.lookup = lookup
+	}
+}
+
+// WithSource enables the expansion of the variable references, as WithLookup does, looking up the variables
+// that are not assigned in the files in the source, e.g. the layered defaults, environment and command line values.
+// A nil source limits the expansion to the variables of the files.
+func WithSource(src Source) Option {
+	if src == nil {
+		return WithLookup(nil)
+	}
+
+	return WithLookup(src.Lookup)
+}
+
+// ExpandInDependencyOrder enables the expansion of the variable references, as WithLookup does, and lets a value
+// reference the variables assigned later in the same file: the assignments of a file are evaluated in the order
+// of their dependencies rather than in the order they are written. A reference to another variable of the file
+// expands to its last assignment in the file, a reference of a variable to itself expands to its previous value.
+// References that form a cycle are reported with ErrReferenceCycle.
+//
+// The included files are loaded before the assignments of the including file are evaluated, so a value can
+// reference the variables of the files included anywhere in the file, the last included file that assigns
+// the variable wins; the variables assigned in the file itself take precedence. The included files see
+// the variables assigned before the including file, but not the variables of the including file.
+// The resulting values still override each other in the order of the statements.
+func ExpandInDependencyOrder() Option {
+	return func(o *options) {
+		o.expand = true
+		o.dependencyOrder = true
+	}
+}
+
+// WithDecrypter enables decryption of the values that start with EncryptedPrefix.
+func WithDecrypter(decrypter Decrypter) Option {
+	return func(o *options) {
+		o.decrypter = decrypter
+	}
+}
+
+// WithResolver registers the resolver of the references of the scheme, e.g. "file" for file:///run/secrets/db.
+// The values that are URIs of the registered schemes are replaced with the values they point to,
+// the other values are kept as they are written. See FileResolver and EnvResolver for the built-in resolvers.
+func WithResolver(scheme string, resolver Resolver) Option {
+	return func(o *options) {
+		o.resolver(scheme).resolver = resolver
+	}
+}
+
+// WithResolverTimeout limits the time the resolver of the scheme may spend on every reference.
+// By default, the time is limited by the context given with WithContext only.
+func WithResolverTimeout(scheme string, timeout time.Duration) Option {
+	return func(o *options) {
+		o.resolver(scheme).timeout = timeout
+	}
+}
+
+// WithContext sets the context passed to the resolvers. By default, context.Background() is used.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithFS makes the parser read the files from fsys instead of the operating system.
+// The include directives cannot refer to the files outside of fsys, so fsys serves as a sandbox.
+// The paths inside fsys are slash-separated, an absolute path is resolved against the root of fsys.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithFilename names the content read by Parse and Read: the include directives are resolved against
+// the directory of the file, as ParseFile does, and the errors point to the file.
+//
+// Without WithFilename or WithFS, the include directives of the content read from io.Reader are rejected
+// with ErrIncludeNotAllowed, so parsing of an untrusted input never reads the local files.
+func WithFilename(name string) Option {
+	return func(o *options) {
+		o.filename = name
+	}
+}
+
+// WithMaxIncludeDepth limits the nesting of the include directives. The default limit is 10.
+func WithMaxIncludeDepth(depth int) Option {
+	return func(o *options) {
+		o.maxIncludeDepth = depth
+	}
+}
+
+// WithRedactPatterns replaces the patterns of the names whose values Vars redacts, see Vars.IsRedacted.
+// A pattern matches the names that contain it in any case. Without patterns, nothing is redacted.
+func WithRedactPatterns(patterns ...string) Option {
+	return func(o *options) {
+		o.redactPatterns = patterns
+	}
+}
+
+// DebugErrors makes the errors quote the text of the file where the problem is. By default, the errors never
+// include the text that may be a part of a value, so they are safe to log even if the values hold secrets.
+func DebugErrors() Option {
+	return func(o *options) {
+		o.debugErrors = true
+	}
+}
+
+// resolver returns the registration of the scheme, creating it if needed.
+func (o *options) resolver(scheme string) *registeredResolver {
+	scheme = strings.ToLower(scheme)
+
+	r, ok := o.resolvers[scheme]
+	if !ok {
+		r = &registeredResolver{}
+		o.resolvers[scheme] = r
+	}
+
+	return r
+}
@@ -0,0 +1,183 @@
+package godenv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+// dependencies evaluates the assignments of a file in the order of their dependencies,
+// see ExpandInDependencyOrder.
+type dependencies struct {
+	l     *loader
+	name  string                     // the name of the file
+	nodes []dependency               // the assignments in the order they are written
+	last  map[string]int             // the index of the last assignment of the variable
+	stack []int                      // the assignments being evaluated, to report cycles
+	state map[int]evaluation         // the assignments that are being evaluated or are evaluated
+	done  map[ast.Statement]Variable // the variables of the evaluated assignments
+
+	included []*Vars // the variables of the included files, in the order of the include directives
+}
+
+type dependency struct {
+	stmt ast.Statement
+	name string
+	pos  token.Position
+}
+
+type evaluation int
+
+const (
+	evaluating evaluation = iota + 1
+	evaluated
+)
+
+// evaluateInOrder returns the variables of the assignments of the file, decrypted, by the statements.
+// The included files are loaded by now, see loadIncludes.
+func (l *loader) evaluateInOrder(name string, file *ast.FileStatement, included map[ast.Statement]*loader) (map[ast.Statement]Variable, error) {
+	d := &dependencies{
+		l:     l,
+		name:  name,
+		last:  make(map[string]int),
+		state: make(map[int]evaluation),
+		done:  make(map[ast.Statement]Variable),
+	}
+
+	for _, stmt := range file.Statements {
+		switch s := stmt.(type) {
+		case *ast.AssignStatement:
+			d.last[s.Name] = len(d.nodes)
+			d.nodes = append(d.nodes, dependency{stmt: s, name: s.Name, pos: s.Pos})
+		case *ast.HeredocStatement:
+			d.last[s.Name] = len(d.nodes)
+			d.nodes = append(d.nodes, dependency{stmt: s, name: s.Name, pos: s.Pos})
+		case *ast.IncludeStatement:
+			d.included = append(d.included, included[s].vars)
+		}
+	}
+
+	for i := range d.nodes {
+		if _, err := d.evaluate(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.done, nil
+}
+
+// evaluate returns the value of the assignment, evaluating the assignments it references first.
+func (d *dependencies) evaluate(i int) (string, error) {
+	node := &d.nodes[i]
+
+	switch d.state[i] {
+	case evaluated:
+		return d.done[node.stmt].Value, nil
+	case evaluating:
+		return "", d.cycle(i)
+	}
+
+	d.state[i] = evaluating
+	d.stack = append(d.stack, i)
+
+	var value string
+
+	origin, pos := OriginFile, node.pos
+
+	switch s := node.stmt.(type) {
+	case *ast.AssignStatement:
+		var refErr error
+
+		lookup := func(ref string) (string, bool) {
+			j, ok := d.target(i, ref)
+			if !ok || refErr != nil {
+				return d.lookup(ref)
+			}
+
+			v, err := d.evaluate(j)
+			if err != nil {
+				refErr = err
+			}
+
+			return v, true
+		}
+
+		v, err := evaluate(s, d.l.o, lookup)
+
+		switch {
+		case refErr != nil:
+			return "", refErr
+		case err != nil:
+			return "", d.l.errorAt(d.name, s.Pos, err)
+		}
+
+		value = v
+		origin, pos = valueOrigin(s, d.l.o, lookup) // the references are evaluated by now
+	case *ast.HeredocStatement:
+		value = s.Value
+	}
+
+	value, err := decrypt(node.name, value, d.l.o)
+	if err != nil {
+		return "", d.l.errorAt(d.name, node.pos, err)
+	}
+
+	d.stack = d.stack[:len(d.stack)-1]
+	d.state[i] = evaluated
+	d.done[node.stmt] = Variable{Name: node.name, Value: value, Pos: position(d.name, pos), Origin: origin}
+
+	return value, nil
+}
+
+// lookup returns the value of the variable that is not assigned in the file: the value assigned by the last
+// included file that assigns the variable, or the value of the variable assigned before the file.
+func (d *dependencies) lookup(name string) (string, bool) {
+	for i := len(d.included) - 1; i >= 0; i-- {
+		if value, ok := d.included[i].Lookup(name); ok {
+			return value, true
+		}
+	}
+
+	return d.l.lookup(name)
+}
+
+// target returns the assignment referenced by the assignment i. A variable that references itself refers to
+// its previous assignment in the file. If there is none, the reference is looked up outside the file.
+func (d *dependencies) target(i int, ref string) (int, bool) {
+	if ref != d.nodes[i].name {
+		j, ok := d.last[ref]
+		return j, ok
+	}
+
+	for j := i - 1; j >= 0; j-- {
+		if d.nodes[j].name == ref {
+			return j, true
+		}
+	}
+
+	return 0, false
+}
+
+// cycle returns the error that describes the cycle of the references that leads back to the assignment i.
+func (d *dependencies) cycle(i int) error {
+	var start int
+
+	for k, j := range d.stack {
+		if j == i {
+			start = k
+			break
+		}
+	}
+
+	// The error points to the file, so the chain has the lines and the columns only.
+	chain := make([]string, 0, len(d.stack)-start+1)
+	for _, j := range d.stack[start:] {
+		chain = append(chain, fmt.Sprintf("%s (%s)", d.nodes[j].name, position("", d.nodes[j].pos)))
+	}
+
+	chain = append(chain, d.nodes[i].name)
+
+	return d.l.errorAt(d.name, d.nodes[i].pos, fmt.Errorf("%w: %s", ErrReferenceCycle, strings.Join(chain, " -> ")))
+}
@@ -0,0 +1,133 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/youla-dev/godenv"
+)
+
+// newFileMode is the permissions of the files created by SetFile, as they may contain secrets.
+const newFileMode = 0o600
+
+// KeysFile returns the names of the variables assigned in the file, see Keys.
+func KeysFile(name string) ([]string, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := Keys(src)
+
+	return keys, fileError(name, err)
+}
+
+// GetFile returns the value of the variable assigned in the file, see Get.
+func GetFile(name, key string) (string, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := Get(src, key)
+
+	return value, fileError(name, err)
+}
+
+// SetFile assigns the value to the variable in the file, see Set. The file is created if it does not exist.
+func SetFile(name, key, value string, opts ...Option) error {
+	src, err := os.ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	res, err := Set(src, key, value, opts...)
+	if err != nil {
+		return fileError(name, err)
+	}
+
+	return WriteFile(name, res)
+}
+
+// UnsetFile removes the assignments of the variable from the file, see Unset.
+func UnsetFile(name, key string) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	res, err := Unset(src, key)
+	if err != nil {
+		return fileError(name, err)
+	}
+
+	return WriteFile(name, res)
+}
+
+// WriteFile replaces the content of the file atomically: the content is written to a temporary file
+// in the same directory, which is then renamed over the file, so the readers never see a partially written file.
+// The permissions of the file are kept, and a symbolic link is followed rather than replaced.
+// A new file is created with the permissions 0600.
+func WriteFile(name string, data []byte) (err error) {
+	if resolved, err := filepath.EvalSymlinks(name); err == nil {
+		name = resolved
+	}
+
+	perm := fs.FileMode(newFileMode)
+
+	info, err := os.Stat(name)
+	switch {
+	case err == nil:
+		perm = info.Mode().Perm()
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// fileError sets the name of the file in the position of *godenv.Error, or prefixes the other errors with the name.
+func fileError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var envErr *godenv.Error
+	if errors.As(err, &envErr) {
+		envErr.Pos.Filename = name
+		return envErr
+	}
+
+	return fmt.Errorf("%s: %w", name, err)
+}
@@ -0,0 +1,167 @@
+// Package patch queries and edits the assignments of the .env files in place.
+//
+// An edit changes the lines of the edited assignments only: the comments, the blank lines, the order of the lines
+// and the notation of the other values are kept intact. The new values are written in the most readable notation
+// that keeps them intact when parsed back: unquoted, in single or double quotes, or as a heredoc.
+//
+// The values are the values as they are written: the substitutions and the references are not evaluated,
+// and the encrypted values are not decrypted.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/edit"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+// ErrNotAssigned is returned when the file has no assignment of the variable.
+var ErrNotAssigned = errors.New("variable is not assigned")
+
+// Option configures how a new variable is added by Set.
+type Option func(*options)
+
+type options struct {
+	after string
+}
+
+// After adds a new variable right after the last assignment of the anchor variable, instead of the end of the file.
+// If the anchor is not assigned, Set returns ErrNotAssigned.
+func After(anchor string) Option {
+	return func(o *options) {
+		o.after = anchor
+	}
+}
+
+// Keys returns the names of the variables assigned in the content, in the order of their first assignment.
+// If the content cannot be parsed, Keys returns *godenv.Error with the position of the problem.
+func Keys(src []byte) ([]string, error) {
+	f, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	seen := make(map[string]bool)
+
+	for _, a := range f.Assignments() {
+		if !seen[a.Name] {
+			seen[a.Name] = true
+			keys = append(keys, a.Name)
+		}
+	}
+
+	return keys, nil
+}
+
+// Get returns the value of the last assignment of the variable, or ErrNotAssigned.
+func Get(src []byte, key string) (string, error) {
+	f, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	a, err := last(f, key)
+	if err != nil {
+		return "", err
+	}
+
+	return a.Value, nil
+}
+
+// Set assigns the value to the variable. The last assignment of the variable is replaced, as it is the one
+// that takes effect. A variable that is not assigned is added to the end of the content, or after the anchor
+// given with After.
+func Set(src []byte, key, value string, opts ...Option) ([]byte, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if !scanner.IsIdentifier(key) {
+		return nil, fmt.Errorf("invalid variable name: %q", key)
+	}
+
+	if !printer.Writable(value) {
+		return nil, fmt.Errorf("variable %s: the value cannot be written", key)
+	}
+
+	f, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if assignments := f.Lookup(key); len(assignments) > 0 {
+		f.Set(assignments[len(assignments)-1], value)
+		return f.Bytes(), nil
+	}
+
+	if o.after == "" {
+		f.Append(key, value)
+		return f.Bytes(), nil
+	}
+
+	anchor, err := last(f, o.after)
+	if err != nil {
+		return nil, err
+	}
+
+	f.InsertAfter(anchor, key, value)
+
+	return f.Bytes(), nil
+}
+
+// Unset removes all the assignments of the variable, or returns ErrNotAssigned.
+func Unset(src []byte, key string) ([]byte, error) {
+	f, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := f.Lookup(key)
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotAssigned, key)
+	}
+
+	for _, a := range assignments {
+		f.Delete(a)
+	}
+
+	return f.Bytes(), nil
+}
+
+// parse parses the content for editing. The syntax errors are returned as *godenv.Error.
+func parse(src []byte) (*edit.File, error) {
+	if !utf8.Valid(src) {
+		return nil, &godenv.Error{Pos: godenv.Position{Line: 1, Column: 1}, Err: errors.New("illegal UTF-8 encoding")}
+	}
+
+	f, err := edit.Parse(src)
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			pos := godenv.Position{Line: syntaxErr.Pos.Line, Column: syntaxErr.Pos.Column}
+			return nil, &godenv.Error{Pos: pos, Err: errors.New(syntaxErr.SafeMsg)}
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// last returns the last assignment of the variable.
+func last(f *edit.File, key string) (*edit.Assignment, error) {
+	assignments := f.Lookup(key)
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotAssigned, key)
+	}
+
+	return assignments[len(assignments)-1], nil
+}
@@ -0,0 +1,201 @@
+package patch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/patch"
+)
+
+const src = `# Database
+DB_HOST="localhost"
+DB_PASSWORD='hunter2'
+
+# API
+API_URL=http://localhost:8080
+DB_HOST=db
+`
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := patch.Keys([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DB_HOST", "DB_PASSWORD", "API_URL"}, keys)
+
+	_, err = patch.Keys([]byte("A=x\uFEFF\n"))
+	require.EqualError(t, err, "1:4: unexpected token: Illegal")
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	value, err := patch.Get([]byte(src), "DB_HOST")
+	require.NoError(t, err)
+	assert.Equal(t, "db", value, "the last assignment takes effect")
+
+	_, err = patch.Get([]byte(src), "MISSING")
+	require.ErrorIs(t, err, patch.ErrNotAssigned)
+	assert.EqualError(t, err, "variable is not assigned: MISSING")
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		src      string
+		key      string
+		value    string
+		opts     []patch.Option
+		expected string
+	}{
+		{
+			name:  "replace the last assignment",
+			src:   src,
+			key:   "DB_HOST",
+			value: "db.internal",
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\n\n# API\n" +
+				"API_URL=http://localhost:8080\nDB_HOST=db.internal\n",
+		},
+		{
+			name:  "quoting",
+			src:   src,
+			key:   "DB_PASSWORD",
+			value: "it's a secret",
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD=\"it's a secret\"\n\n# API\n" +
+				"API_URL=http://localhost:8080\nDB_HOST=db\n",
+		},
+		{
+			name:  "append",
+			src:   src,
+			key:   "DEBUG",
+			value: "true",
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\n\n# API\n" +
+				"API_URL=http://localhost:8080\nDB_HOST=db\nDEBUG=true\n",
+		},
+		{
+			name:  "after the anchor",
+			src:   src,
+			key:   "DB_USER",
+			value: "admin",
+			opts:  []patch.Option{patch.After("DB_PASSWORD")},
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\nDB_USER=admin\n\n# API\n" +
+				"API_URL=http://localhost:8080\nDB_HOST=db\n",
+		},
+		{
+			name:  "the anchor is ignored for the assigned variables",
+			src:   src,
+			key:   "API_URL",
+			value: "https://example.com",
+			opts:  []patch.Option{patch.After("DB_PASSWORD")},
+			expected: "# Database\nDB_HOST=\"localhost\"\nDB_PASSWORD='hunter2'\n\n# API\n" +
+				"API_URL=https://example.com\nDB_HOST=db\n",
+		},
+		{
+			name:     "heredoc",
+			src:      "A=1",
+			key:      "JSON",
+			value:    "{\n  \"a\": 'b'\n}",
+			expected: "A=1\nJSON<<EOF\n{\n  \"a\": 'b'\n}\nEOF\n",
+		},
+		{
+			name:     "empty content",
+			key:      "A",
+			value:    "1",
+			expected: "A=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := patch.Set([]byte(tt.src), tt.key, tt.value, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(res))
+
+			value, err := patch.Get(res, tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestSet_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := patch.Set([]byte(src), "INVALID NAME", "1")
+	assert.EqualError(t, err, `invalid variable name: "INVALID NAME"`)
+
+	_, err = patch.Set([]byte(src), "DB_USER", "x\r\ny'\"")
+	assert.EqualError(t, err, "variable DB_USER: the value cannot be written")
+
+	_, err = patch.Set([]byte(src), "DB_USER", "admin", patch.After("MISSING"))
+	require.ErrorIs(t, err, patch.ErrNotAssigned)
+
+	_, err = patch.Set([]byte("A=1\nB=\"unterminated\n"), "A", "2")
+
+	var envErr *godenv.Error
+	require.ErrorAs(t, err, &envErr)
+	assert.Equal(t, 2, envErr.Pos.Line)
+}
+
+func TestUnset(t *testing.T) {
+	t.Parallel()
+
+	res, err := patch.Unset([]byte(src), "DB_HOST")
+	require.NoError(t, err)
+	assert.Equal(t, "# Database\nDB_PASSWORD='hunter2'\n\n# API\nAPI_URL=http://localhost:8080\n", string(res))
+
+	_, err = patch.Unset([]byte(src), "MISSING")
+	require.ErrorIs(t, err, patch.ErrNotAssigned)
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, patch.SetFile(name, "A", "1"))
+
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "a new file is created private")
+
+	require.NoError(t, os.Chmod(name, 0o640))
+	require.NoError(t, patch.SetFile(name, "B", "two words"))
+	require.NoError(t, patch.SetFile(name, "C", "3", patch.After("A")))
+
+	info, err = os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm(), "the permissions are kept")
+
+	keys, err := patch.KeysFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "C", "B"}, keys)
+
+	value, err := patch.GetFile(name, "B")
+	require.NoError(t, err)
+	assert.Equal(t, "two words", value)
+
+	require.NoError(t, patch.UnsetFile(name, "A"))
+
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "C=3\nB='two words'\n", string(content))
+
+	err = patch.UnsetFile(name, "A")
+	require.ErrorIs(t, err, patch.ErrNotAssigned)
+	assert.EqualError(t, err, name+": variable is not assigned: A")
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left")
+}
@@ -0,0 +1,107 @@
+package godenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Redacted replaces the values of the secrets when Vars is printed or logged.
+const Redacted = "***"
+
+// DefaultRedactPatterns returns the patterns of the names whose values are redacted by default:
+// the names that contain PASSWORD, TOKEN, SECRET, KEY and the like.
+func DefaultRedactPatterns() []string {
+	return []string{"PASSWORD", "PASSWD", "TOKEN", "SECRET", "KEY", "CREDENTIAL", "PRIVATE"}
+}
+
+// IsRedacted reports whether the value of the variable is replaced with Redacted when Vars is printed
+// or logged: the name contains one of the patterns, in any case.
+// The patterns are DefaultRedactPatterns, unless they are set with WithRedactPatterns.
+func (v *Vars) IsRedacted(key string) bool {
+	upper := strings.ToUpper(key)
+
+	for _, pattern := range v.redact {
+		if pattern != "" && strings.Contains(upper, strings.ToUpper(pattern)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// display returns the value of the variable, or Redacted if the variable is a secret.
+func (v *Vars) display(key string) string {
+	if v.IsRedacted(key) {
+		return Redacted
+	}
+
+	return v.Get(key)
+}
+
+// String returns the variables in the {KEY=value, ...} form, in the order of their first assignment,
+// with the values of the secrets redacted.
+func (v *Vars) String() string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	var b strings.Builder
+
+	b.WriteByte('{')
+
+	for i, key := range v.keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(v.display(key))
+	}
+
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// GoString returns the variables as a Go expression, with the values of the secrets redacted.
+// It is used by the %#v verb.
+func (v *Vars) GoString() string {
+	if v == nil {
+		return "(*godenv.Vars)(nil)"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("&godenv.Vars{")
+
+	for i, key := range v.keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(strconv.Quote(key))
+		b.WriteString(": ")
+		b.WriteString(strconv.Quote(v.display(key)))
+	}
+
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// Format implements fmt.Formatter, so that no verb prints the values of the secrets:
+// %v and %s print String, %#v prints GoString, and %q prints String quoted.
+func (v *Vars) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, v.GoString())
+	case verb == 'v', verb == 's':
+		fmt.Fprint(f, v.String())
+	case verb == 'q':
+		fmt.Fprint(f, strconv.Quote(v.String()))
+	default:
+		fmt.Fprintf(f, "%%!%c(*godenv.Vars=%s)", verb, v.String())
+	}
+}
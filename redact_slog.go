@@ -0,0 +1,21 @@
+//go:build go1.21
+// +build go1.21
+
+package godenv
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer: the variables are logged as a group, with the values of the secrets
+// redacted.
+func (v *Vars) LogValue() slog.Value {
+	if v == nil {
+		return slog.GroupValue()
+	}
+
+	attrs := make([]slog.Attr, 0, len(v.keys))
+	for _, key := range v.keys {
+		attrs = append(attrs, slog.String(key, v.display(key)))
+	}
+
+	return slog.GroupValue(attrs...)
+}
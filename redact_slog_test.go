@@ -0,0 +1,42 @@
+//go:build go1.21
+// +build go1.21
+
+package godenv_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+func TestVars_LogValue(t *testing.T) {
+	t.Parallel()
+
+	vars, err := godenv.Read(strings.NewReader(secrets))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}))
+	logger.Info("loaded", "env", vars)
+
+	assert.JSONEq(t, `{
+		"level": "INFO",
+		"msg": "loaded",
+		"env": {"HOST": "localhost", "DB_PASSWORD": "***", "api_key": "***"}
+	}`, buf.String())
+}
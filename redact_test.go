@@ -0,0 +1,115 @@
+package godenv_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+const secrets = "HOST=localhost\nDB_PASSWORD=hunter2\napi_key=abc\n"
+
+func TestVars_Redaction(t *testing.T) {
+	t.Parallel()
+
+	vars, err := godenv.Read(strings.NewReader(secrets))
+	require.NoError(t, err)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "%v", expected: "{HOST=localhost, DB_PASSWORD=***, api_key=***}"},
+		{format: "%+v", expected: "{HOST=localhost, DB_PASSWORD=***, api_key=***}"},
+		{format: "%s", expected: "{HOST=localhost, DB_PASSWORD=***, api_key=***}"},
+		{format: "%q", expected: `"{HOST=localhost, DB_PASSWORD=***, api_key=***}"`},
+		{format: "%#v", expected: `&godenv.Vars{"HOST": "localhost", "DB_PASSWORD": "***", "api_key": "***"}`},
+		{format: "%x", expected: "%!x(*godenv.Vars={HOST=localhost, DB_PASSWORD=***, api_key=***})"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, fmt.Sprintf(tc.format, vars), tc.format)
+	}
+
+	assert.Equal(t, "{HOST=localhost, DB_PASSWORD=***, api_key=***}\n", fmt.Sprintln(vars))
+
+	assert.False(t, vars.IsRedacted("HOST"))
+	assert.True(t, vars.IsRedacted("DB_PASSWORD"))
+	assert.True(t, vars.IsRedacted("api_key"))
+	assert.Equal(t, "hunter2", vars.Get("DB_PASSWORD"), "the values are not changed")
+}
+
+func TestWithRedactPatterns(t *testing.T) {
+	t.Parallel()
+
+	vars, err := godenv.Read(strings.NewReader(secrets+"DSN=postgres://user:pass@db\n"), godenv.WithRedactPatterns("dsn", "PASSWORD"))
+	require.NoError(t, err)
+	assert.Equal(t, "{HOST=localhost, DB_PASSWORD=***, api_key=abc, DSN=***}", vars.String())
+
+	vars, err = godenv.Read(strings.NewReader(secrets), godenv.WithRedactPatterns())
+	require.NoError(t, err)
+	assert.Equal(t, "{HOST=localhost, DB_PASSWORD=hunter2, api_key=abc}", vars.String())
+
+	assert.Equal(t, "{A=1, TOKEN=***}", godenv.NewVars(map[string]string{"TOKEN": "t", "A": "1"}).String())
+}
+
+func TestRead_ErrorsWithoutValues(t *testing.T) {
+	t.Parallel()
+
+	failing := godenv.CommandRunnerFunc(func(string) (string, error) {
+		return "", errors.New("exit status 1")
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []godenv.Option
+		expected string
+		debug    string
+	}{
+		{
+			name:     "illegal token",
+			input:    "FOO=\"hunter2\"x\n",
+			expected: "1:14: unexpected token: Illegal",
+			debug:    "1:14: unexpected token: Illegal(x)",
+		},
+		{
+			name:     "unexpected value",
+			input:    "\"hunter2\"\n",
+			expected: "1:1: unexpected statement: VALUE",
+			debug:    `1:1: unexpected statement: VALUE("hunter2")`,
+		},
+		{
+			name:     "whitespace is quoted",
+			input:    "FOO='hunter2' x\n",
+			expected: "1:14: unexpected token: SPACE( )",
+			debug:    "1:14: unexpected token: SPACE( )",
+		},
+		{
+			name:     "failed command",
+			input:    "TOKEN=$(vault read -field=token secret/hunter2)\n",
+			opts:     []godenv.Option{godenv.WithCommandRunner(failing)},
+			expected: "1:1: variable TOKEN: command substitution: exit status 1",
+			debug:    "1:1: variable TOKEN: $(vault read -field=token secret/hunter2): exit status 1",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := godenv.Read(strings.NewReader(tc.input), tc.opts...)
+			assert.EqualError(t, err, tc.expected)
+
+			_, err = godenv.Read(strings.NewReader(tc.input), append(tc.opts, godenv.DebugErrors())...)
+			assert.EqualError(t, err, tc.debug)
+		})
+	}
+}
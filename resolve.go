@@ -0,0 +1,189 @@
+package godenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnresolved is returned by the resolvers when the referenced value does not exist.
+var ErrUnresolved = errors.New("reference cannot be resolved")
+
+// Resolver resolves the references to the values kept elsewhere, e.g. DB_PASSWORD=file:///run/secrets/db
+// or API_KEY=vault://secret/api#key. The resolvers are registered by the scheme of the URI with WithResolver.
+//
+// The references are resolved after the files are loaded, so a reference overridden by a later assignment
+// is never resolved, and every reference is resolved once per parse.
+type Resolver interface {
+	// Resolve returns the value the reference points to. The resolver must give up when ctx is done.
+	// The error must not include the value.
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as Resolver.
+type ResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f ResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+// FileResolver returns the resolver of the file:// references: the value is the content of the file
+// with the surrounding whitespace trimmed. The path of file:///run/secrets/db is absolute,
+// the path of file://secrets/db is relative to the working directory.
+//
+// A read of a local file cannot be interrupted, so the context is checked before and after the read:
+// the resolver gives up when ctx is done, but a read that has begun is completed first.
+func FileResolver() Resolver {
+	return ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		b, err := os.ReadFile(ref.Host + ref.Path)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: file %s does not exist", ErrUnresolved, ref.Host+ref.Path)
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(string(b)), nil
+	})
+}
+
+// EnvResolver returns the resolver of the env:// references: the value of env://NAME is the value
+// of the NAME environment variable.
+func EnvResolver() Resolver {
+	return ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		value, ok := os.LookupEnv(ref.Host)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrUnresolved, ref.Host)
+		}
+
+		return value, nil
+	})
+}
+
+// CachingResolver returns the resolver that remembers the values resolved by the resolver,
+// so the same reference is resolved once even if several files are parsed. The errors are not cached.
+// The resolver is safe for concurrent use.
+func CachingResolver(resolver Resolver) Resolver {
+	var (
+		mu     sync.Mutex
+		values = make(map[string]string)
+	)
+
+	return ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		key := ref.String()
+
+		mu.Lock()
+		value, ok := values[key]
+		mu.Unlock()
+
+		if ok {
+			return value, nil
+		}
+
+		value, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+
+		mu.Lock()
+		values[key] = value
+		mu.Unlock()
+
+		return value, nil
+	})
+}
+
+// registeredResolver is a resolver registered for a scheme.
+type registeredResolver struct {
+	resolver Resolver
+	timeout  time.Duration
+}
+
+// reference returns the reference and its resolver, if the value is a URI of a registered scheme.
+func reference(value string, o *options) (*url.URL, *registeredResolver) {
+	i := strings.Index(value, "://")
+	if i < 0 {
+		return nil, nil
+	}
+
+	r, ok := o.resolvers[strings.ToLower(value[:i])]
+	if !ok || r.resolver == nil {
+		return nil, nil
+	}
+
+	ref, err := url.Parse(value)
+	if err != nil {
+		return nil, nil
+	}
+
+	return ref, r
+}
+
+// resolveReferences replaces the references with the values they point to.
+func (l *loader) resolveReferences() error {
+	ctx := l.o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	resolved := make(map[string]string)
+
+	for _, name := range l.vars.keys {
+		includedFrom, ok := l.references[name]
+		if !ok {
+			continue
+		}
+
+		variable := l.vars.vars[name]
+		ref, r := reference(variable.Value, l.o)
+
+		value, ok := resolved[variable.Value]
+		if !ok {
+			var err error
+
+			value, err = resolveReference(ctx, ref, r)
+			if err != nil {
+				if l.o.debugErrors {
+					err = fmt.Errorf("variable %s: resolve %s: %w", name, ref, err)
+				} else {
+					err = fmt.Errorf("variable %s: resolve %s: %w", name, ref.Scheme, err)
+				}
+
+				return &Error{Pos: variable.Pos, IncludedFrom: includedFrom, Err: err}
+			}
+
+			resolved[variable.Value] = value
+		}
+
+		variable.Value = value
+		l.vars.vars[name] = variable // the value is replaced, not overridden
+	}
+
+	return nil
+}
+
+func resolveReference(ctx context.Context, ref *url.URL, r *registeredResolver) (string, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	return r.resolver.Resolve(ctx, ref)
+}
@@ -0,0 +1,167 @@
+package godenv_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+// fakeVault resolves vault://path#key references from the map, counting the calls.
+type fakeVault struct {
+	secrets map[string]string
+	calls   int32
+}
+
+func (v *fakeVault) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	atomic.AddInt32(&v.calls, 1)
+
+	value, ok := v.secrets[ref.Host+ref.Path+"#"+ref.Fragment]
+	if !ok {
+		return "", godenv.ErrUnresolved
+	}
+
+	return value, nil
+}
+
+func TestResolver(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db")
+	require.NoError(t, os.WriteFile(secret, []byte("hunter2\n"), 0o600))
+
+	setenv(t, "GODENV_TEST_TOKEN", "t0ken")
+
+	vault := &fakeVault{secrets: map[string]string{"secret/api#key": "abc"}}
+
+	input := "DB_PASSWORD=file://" + filepath.ToSlash(secret) + "\n" +
+		"API_TOKEN=env://GODENV_TEST_TOKEN\n" +
+		"API_KEY=vault://secret/api#key\n" +
+		"API_KEY_COPY=vault://secret/api#key\n" +
+		"HOMEPAGE=https://example.com\n" +
+		"OVERRIDDEN=vault://secret/missing#key\n" +
+		"OVERRIDDEN=plain\n"
+
+	vars, err := godenv.Parse(strings.NewReader(input),
+		godenv.WithResolver("file", godenv.FileResolver()),
+		godenv.WithResolver("env", godenv.EnvResolver()),
+		godenv.WithResolver("VAULT", vault),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_PASSWORD":  "hunter2",
+		"API_TOKEN":    "t0ken",
+		"API_KEY":      "abc",
+		"API_KEY_COPY": "abc",
+		"HOMEPAGE":     "https://example.com",
+		"OVERRIDDEN":   "plain",
+	}, vars)
+	assert.Equal(t, int32(1), vault.calls, "the reference is resolved once, the overridden one is not resolved")
+
+	vars, err = godenv.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, "env://GODENV_TEST_TOKEN", vars["API_TOKEN"], "the references are kept without resolvers")
+}
+
+func TestResolver_Errors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.env"), []byte("A=1\n#include secrets.env\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secrets.env"), []byte("\nDB_PASSWORD=env://GODENV_TEST_MISSING\n"), 0o600))
+
+	_, err := godenv.ParseFile(filepath.Join(dir, "base.env"), godenv.WithResolver("env", godenv.EnvResolver()))
+
+	var envErr *godenv.Error
+	require.ErrorAs(t, err, &envErr)
+	assert.ErrorIs(t, err, godenv.ErrUnresolved)
+	assert.Equal(t, godenv.Position{Filename: filepath.Join(dir, "secrets.env"), Line: 2, Column: 1}, envErr.Pos)
+	assert.Equal(t, []godenv.Position{{Filename: filepath.Join(dir, "base.env"), Line: 2, Column: 1}}, envErr.IncludedFrom)
+	assert.EqualError(t, envErr.Err, "variable DB_PASSWORD: resolve env: reference cannot be resolved: "+
+		"environment variable GODENV_TEST_MISSING is not set")
+
+	_, err = godenv.Parse(strings.NewReader("DB_PASSWORD=file:///does/not/exist\n"), godenv.WithResolver("file", godenv.FileResolver()))
+	assert.EqualError(t, err, "1:1: variable DB_PASSWORD: resolve file: reference cannot be resolved: "+
+		"file /does/not/exist does not exist")
+
+	_, err = godenv.Parse(strings.NewReader("DB_PASSWORD=file:///does/not/exist\n"),
+		godenv.WithResolver("file", godenv.FileResolver()), godenv.DebugErrors())
+	assert.EqualError(t, err, "1:1: variable DB_PASSWORD: resolve file:///does/not/exist: reference cannot be resolved: "+
+		"file /does/not/exist does not exist")
+}
+
+func TestResolver_Timeout(t *testing.T) {
+	t.Parallel()
+
+	slow := godenv.ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Minute):
+			return "slow", nil
+		}
+	})
+
+	input := "A=slow://value\n"
+
+	_, err := godenv.Parse(strings.NewReader(input),
+		godenv.WithResolver("slow", slow),
+		godenv.WithResolverTimeout("slow", 10*time.Millisecond),
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = godenv.Parse(strings.NewReader(input), godenv.WithResolver("slow", slow), godenv.WithContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileResolver_Canceled(t *testing.T) {
+	t.Parallel()
+
+	secret := filepath.Join(t.TempDir(), "db")
+	require.NoError(t, os.WriteFile(secret, []byte("hunter2\n"), 0o600))
+
+	ref, err := url.Parse("file://" + filepath.ToSlash(secret))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = godenv.FileResolver().Resolve(ctx, ref)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCachingResolver(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+
+	resolver := godenv.CachingResolver(godenv.ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return "", errors.New("unavailable")
+		}
+
+		return "value", nil
+	}))
+
+	input := strings.NewReader("A=ref://a\n")
+	_, err := godenv.Parse(input, godenv.WithResolver("ref", resolver))
+	assert.EqualError(t, err, "1:1: variable A: resolve ref: unavailable", "the errors are not cached")
+
+	for i := 0; i < 2; i++ {
+		vars, err := godenv.Parse(strings.NewReader("A=ref://a\n"), godenv.WithResolver("ref", resolver))
+		require.NoError(t, err)
+		assert.Equal(t, "value", vars["A"])
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
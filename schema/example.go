@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"bytes"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/printer"
+)
+
+// IsSecret reports whether the name of the variable looks like the name of a secret: it contains
+// one of godenv.DefaultRedactPatterns, such as PASSWORD, TOKEN, SECRET or KEY, in any case.
+func IsSecret(name string) bool {
+	return godenv.NewVars(nil).IsRedacted(name)
+}
+
+// ExampleOption configures the generation of the example.
+type ExampleOption func(*exampleOptions)
+
+type exampleOptions struct {
+	typeHints  bool
+	keepValues bool
+}
+
+// TypeHints replaces the values with the placeholders of their types, e.g. <int> or <url>.
+func TypeHints() ExampleOption {
+	return func(o *exampleOptions) {
+		o.typeHints = true
+	}
+}
+
+// KeepValues copies the values of the variables that are not secrets.
+func KeepValues() ExampleOption {
+	return func(o *exampleOptions) {
+		o.keepValues = true
+	}
+}
+
+// Example generates the example of the .env file: the file with the same comments, include directives,
+// blank lines and keys in the same order, but without the values. By default, the values are blanked.
+// The values of the secrets, see IsSecret, are never copied.
+// If the content cannot be parsed, Example returns *godenv.Error with the position of the problem.
+func Example(src []byte, opts ...ExampleOption) ([]byte, error) {
+	o := &exampleOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	file, err := parse("", src)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]ast.Statement, 0, len(file.Statements))
+
+	for _, stmt := range file.Statements {
+		switch s := stmt.(type) {
+		case *ast.AssignStatement:
+			stmt = o.example(s.Name, s.Value)
+		case *ast.HeredocStatement:
+			stmt = o.example(s.Name, s.Value)
+		}
+
+		statements = append(statements, stmt)
+	}
+
+	var buf bytes.Buffer
+
+	if err := printer.Fprint(&buf, &ast.FileStatement{Statements: statements}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// example returns the assignment of the example value.
+func (o *exampleOptions) example(name, value string) ast.Statement {
+	switch {
+	case IsSecret(name):
+		value = ""
+	case o.keepValues:
+	case o.typeHints && value != "":
+		value = "<" + guessType(value).Name + ">"
+	default:
+		value = ""
+	}
+
+	return printer.Assign(name, value)
+}
+
+// guessType returns the most specific type that the value matches.
+func guessType(value string) *Type {
+	for _, t := range []*Type{Int, Bool, Duration, URL} {
+		if t.Validate(value) == nil {
+			return t
+		}
+	}
+
+	return String
+}
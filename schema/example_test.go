@@ -0,0 +1,156 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+const env = `# Database
+DB_HOST=db.internal
+DB_PORT=5432
+DB_PASSWORD=hunter2
+
+#include common.env
+
+# HTTP
+HTTP_URL="https://example.com/api"
+DEBUG=true
+TIMEOUT=1m30s
+API_KEY=abc
+GREETING<<EOF
+Hello,
+world
+EOF
+EMPTY=
+`
+
+func TestExample(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []schema.ExampleOption
+		expected string
+	}{
+		{
+			name: "blank values",
+			expected: `# Database
+DB_HOST=
+DB_PORT=
+DB_PASSWORD=
+
+#include common.env
+
+# HTTP
+HTTP_URL=
+DEBUG=
+TIMEOUT=
+API_KEY=
+GREETING=
+EMPTY=
+`,
+		},
+		{
+			name: "type hints",
+			opts: []schema.ExampleOption{schema.TypeHints()},
+			expected: `# Database
+DB_HOST='<string>'
+DB_PORT='<int>'
+DB_PASSWORD=
+
+#include common.env
+
+# HTTP
+HTTP_URL='<url>'
+DEBUG='<bool>'
+TIMEOUT='<duration>'
+API_KEY=
+GREETING='<string>'
+EMPTY=
+`,
+		},
+		{
+			name: "keep values",
+			opts: []schema.ExampleOption{schema.KeepValues()},
+			expected: `# Database
+DB_HOST=db.internal
+DB_PORT=5432
+DB_PASSWORD=
+
+#include common.env
+
+# HTTP
+HTTP_URL=https://example.com/api
+DEBUG=true
+TIMEOUT=1m30s
+API_KEY=
+GREETING="Hello,\nworld"
+EMPTY=
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := schema.Example([]byte(env), tc.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+
+			// the example is a valid schema
+			_, err = schema.Parse(".env.example", out)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestExample_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := schema.Example([]byte("FOO= bar\n"))
+
+	var posErr *godenv.Error
+	require.ErrorAs(t, err, &posErr)
+	assert.Equal(t, godenv.Position{Line: 1, Column: 5}, posErr.Pos)
+}
+
+func TestIsSecret(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"DB_PASSWORD", "GITHUB_TOKEN", "client_secret", "API_KEY", "PRIVATE_KEY_PEM"} {
+		assert.True(t, schema.IsSecret(name), name)
+	}
+
+	for _, name := range []string{"DB_HOST", "PORT", "DEBUG"} {
+		assert.False(t, schema.IsSecret(name), name)
+	}
+}
+
+func TestSchema_Missing(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Parse(".env.example", []byte(example))
+	require.NoError(t, err)
+
+	vars := readVars(t, "HTTP_ADDR=\nLOG_LEVEL=info\nPORT=80\nTIMEOUT=1s\nRELEASE=app-1\nDEBUG=false\nEXTRA=1\n")
+	assert.Equal(t, []schema.Violation{
+		{
+			Key:       "WORKERS",
+			Message:   "variable WORKERS is not set",
+			SchemaPos: godenv.Position{Filename: ".env.example", Line: 26, Column: 1},
+		},
+		{
+			Key:       "NAME",
+			Message:   "variable NAME is not set",
+			SchemaPos: godenv.Position{Filename: ".env.example", Line: 27, Column: 1},
+		},
+	}, s.Missing(vars))
+}
@@ -0,0 +1,212 @@
+// Package schema validates .env files against a schema: a file that declares the variables, their types,
+// defaults and descriptions.
+//
+// The schema is an .env file, usually .env.example or .env.schema, with the annotated comments.
+// The comments right above an assignment describe the variable:
+//
+//	# The address of the HTTP server.
+//	# @type url
+//	# @required
+//	HTTP_ADDR=http://localhost:8080
+//
+//	# @type enum(debug, info, warn, error)
+//	# @default info
+//	LOG_LEVEL=
+//
+// The annotations are:
+//   - "@type <type>" declares the type of the value: string (default), int, bool, url, port, duration,
+//     "enum(a, b, ...)" or "regex(<pattern>)". The regular expression must match the whole value.
+//   - "@required" declares that the variable must be set to a non-empty value.
+//   - "@default <value>" declares the value that is used when the variable is not set.
+//
+// The other comment lines are the description of the variable. The values assigned in the schema are examples,
+// they are not validated.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/parser"
+	"github.com/youla-dev/godenv/internal/scanner"
+	"github.com/youla-dev/godenv/internal/token"
+)
+
+const annotationPrefix = "@"
+
+// Field declares a variable.
+type Field struct {
+	Name        string
+	Type        *Type
+	Required    bool
+	Default     string
+	HasDefault  bool
+	Description string
+	Example     string          // the value assigned in the schema
+	Pos         godenv.Position // the assignment in the schema
+}
+
+// Schema is a set of the declared variables.
+type Schema struct {
+	Fields []*Field // in the order of the declaration
+}
+
+// Field returns the declaration of the variable, or nil if the variable is not declared.
+func (s *Schema) Field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// ParseFile reads the schema from the file.
+func ParseFile(filename string) (*Schema, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(filename, src)
+}
+
+// Parse parses the schema. The filename is used in the positions only.
+// If the schema is invalid, Parse returns *godenv.Error with the position of the problem.
+func Parse(filename string, src []byte) (*Schema, error) {
+	file, err := parse(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{}
+
+	var comments []*ast.CommentStatement
+
+	for _, stmt := range file.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.CommentStatement:
+			comments = append(comments, stmt)
+			continue
+		case *ast.AssignStatement:
+			err = s.declare(filename, stmt.Name, stmt.Value, stmt.Pos, comments)
+		case *ast.HeredocStatement:
+			err = s.declare(filename, stmt.Name, stmt.Value, stmt.Pos, comments)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		comments = nil
+	}
+
+	return s, nil
+}
+
+// declare adds the field described by the comments above the assignment.
+func (s *Schema) declare(filename, name, example string, pos token.Position, comments []*ast.CommentStatement) error {
+	if s.Field(name) != nil {
+		return errorAt(filename, pos, fmt.Errorf("variable %s is declared twice", name))
+	}
+
+	f := &Field{
+		Name:    name,
+		Type:    String,
+		Example: example,
+		Pos:     godenv.Position{Filename: filename, Line: pos.Line, Column: pos.Column},
+	}
+
+	var description []string
+
+	for _, comment := range comments {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Value, "#"))
+
+		if !strings.HasPrefix(text, annotationPrefix) {
+			description = append(description, text)
+			continue
+		}
+
+		if err := f.annotate(text); err != nil {
+			return errorAt(filename, comment.Pos, fmt.Errorf("variable %s: %w", name, err))
+		}
+	}
+
+	if f.HasDefault {
+		if err := f.Type.Validate(f.Default); err != nil {
+			return errorAt(filename, pos, fmt.Errorf("variable %s: invalid default: %w", name, err))
+		}
+	}
+
+	f.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	s.Fields = append(s.Fields, f)
+
+	return nil
+}
+
+// annotate applies the "@name argument" annotation.
+func (f *Field) annotate(annotation string) error {
+	name, arg := annotation, ""
+	if i := strings.IndexAny(annotation, " \t"); i >= 0 {
+		name, arg = annotation[:i], strings.TrimSpace(annotation[i:])
+	}
+
+	switch name {
+	case "@type":
+		t, err := ParseType(arg)
+		if err != nil {
+			return err
+		}
+
+		f.Type = t
+	case "@required":
+		if arg != "" {
+			return fmt.Errorf("unexpected argument of @required: %q", arg)
+		}
+
+		f.Required = true
+	case "@default":
+		f.Default, f.HasDefault = arg, true
+	default:
+		return fmt.Errorf("unknown annotation %s", name)
+	}
+
+	return nil
+}
+
+// parse parses the .env file. The filename is used in the positions of the errors only.
+func parse(filename string, src []byte) (*ast.FileStatement, error) {
+	if !utf8.Valid(src) {
+		return nil, errorAt(filename, token.Position{Line: 1, Column: 1}, errors.New("illegal UTF-8 encoding"))
+	}
+
+	statement, err := parser.New(scanner.New(string(src))).Parse()
+	if err != nil {
+		var syntaxErr *parser.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, errorAt(filename, syntaxErr.Pos, errors.New(syntaxErr.SafeMsg))
+		}
+
+		return nil, err
+	}
+
+	file, ok := statement.(*ast.FileStatement)
+	if !ok {
+		return nil, fmt.Errorf("unexpected statement: %T", statement)
+	}
+
+	return file, nil
+}
+
+func errorAt(filename string, pos token.Position, err error) error {
+	return &godenv.Error{
+		Pos: godenv.Position{Filename: filename, Line: pos.Line, Column: pos.Column},
+		Err: err,
+	}
+}
@@ -0,0 +1,181 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+const example = `# Settings of the service.
+
+# The address of the HTTP server.
+# @type url
+# @required
+HTTP_ADDR=http://localhost:8080
+
+# @type enum(debug, info, warn, error)
+# @default info
+LOG_LEVEL=
+
+# @type port
+PORT=8080
+
+# @type duration
+# @default 30s
+TIMEOUT=
+
+# @type regex([a-z]+-[0-9]+)
+RELEASE=app-1
+
+# @type bool
+DEBUG=false
+
+# @type int
+WORKERS=4
+NAME=example
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Parse(".env.example", []byte(example))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		names = append(names, f.Name)
+	}
+
+	assert.Equal(t, []string{"HTTP_ADDR", "LOG_LEVEL", "PORT", "TIMEOUT", "RELEASE", "DEBUG", "WORKERS", "NAME"}, names)
+
+	addr := s.Field("HTTP_ADDR")
+	assert.Equal(t, schema.URL, addr.Type)
+	assert.True(t, addr.Required)
+	assert.False(t, addr.HasDefault)
+	assert.Equal(t, "The address of the HTTP server.", addr.Description)
+	assert.Equal(t, "http://localhost:8080", addr.Example)
+	assert.Equal(t, godenv.Position{Filename: ".env.example", Line: 6, Column: 1}, addr.Pos)
+
+	level := s.Field("LOG_LEVEL")
+	assert.Equal(t, "enum(debug, info, warn, error)", level.Type.String())
+	assert.False(t, level.Required)
+	assert.True(t, level.HasDefault)
+	assert.Equal(t, "info", level.Default)
+	assert.Empty(t, level.Description)
+
+	assert.Equal(t, schema.String, s.Field("NAME").Type)
+	assert.Nil(t, s.Field("UNKNOWN"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "unknown type",
+			input:    "# @type float\nFOO=1\n",
+			expected: `.env.example:1:1: variable FOO: unknown type "float"`,
+		},
+		{
+			name:     "unknown annotation",
+			input:    "FOO=1\n\n# @secret\nBAR=2\n",
+			expected: ".env.example:3:1: variable BAR: unknown annotation @secret",
+		},
+		{
+			name:     "empty enum",
+			input:    "# @type enum( , )\nFOO=1\n",
+			expected: ".env.example:1:1: variable FOO: enum must have values",
+		},
+		{
+			name:     "invalid regex",
+			input:    "# @type regex([a-z)\nFOO=1\n",
+			expected: ".env.example:1:1: variable FOO: invalid regular expression: error parsing regexp: missing closing ]: `[a-z`",
+		},
+		{
+			name:     "invalid default",
+			input:    "# @type int\n# @default many\nFOO=\n",
+			expected: ".env.example:3:1: variable FOO: invalid default: must be an integer",
+		},
+		{
+			name:     "argument of required",
+			input:    "# @required yes\nFOO=\n",
+			expected: `.env.example:1:1: variable FOO: unexpected argument of @required: "yes"`,
+		},
+		{
+			name:     "declared twice",
+			input:    "FOO=1\nFOO=2\n",
+			expected: ".env.example:2:1: variable FOO is declared twice",
+		},
+		{
+			name:     "syntax error",
+			input:    "FOO= 1\n",
+			expected: ".env.example:1:5: unexpected token: SPACE( )",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := schema.Parse(".env.example", []byte(tc.input))
+			require.Error(t, err)
+
+			var posErr *godenv.Error
+			require.ErrorAs(t, err, &posErr)
+			assert.Equal(t, tc.expected, err.Error())
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		typ     string
+		valid   []string
+		invalid []string
+	}{
+		{typ: "string", valid: []string{"", "anything"}},
+		{typ: "int", valid: []string{"0", "-42", "9000"}, invalid: []string{"4.2", "ten", "0x10"}},
+		{typ: "bool", valid: []string{"true", "false", "1", "0", "yes", "No", "on", "OFF"}, invalid: []string{"t", "F", "2"}},
+		{
+			typ:     "url",
+			valid:   []string{"https://example.com/path", "postgres://user:pass@db:5432/app", "mailto:admin@example.com"},
+			invalid: []string{"example.com", "/path", "http://"},
+		},
+		{typ: "port", valid: []string{"1", "8080", "65535"}, invalid: []string{"0", "65536", "http"}},
+		{typ: "duration", valid: []string{"1s", "1m30s", "0"}, invalid: []string{"1", "a minute"}},
+		{typ: "enum(debug, info)", valid: []string{"debug", "info"}, invalid: []string{"warn", "Debug", ""}},
+		{typ: "regex([a-z]+-[0-9]+)", valid: []string{"app-1"}, invalid: []string{"app-", "x app-1", "app-1 x"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.typ, func(t *testing.T) {
+			t.Parallel()
+
+			typ, err := schema.ParseType(tc.typ)
+			require.NoError(t, err)
+			assert.Equal(t, tc.typ, typ.String())
+
+			for _, value := range tc.valid {
+				assert.NoError(t, typ.Validate(value), value)
+			}
+
+			for _, value := range tc.invalid {
+				assert.Error(t, typ.Validate(value), value)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youla-dev/godenv"
+)
+
+// Type is a type of the value.
+type Type struct {
+	Name     string
+	validate func(value string) error
+}
+
+// The types without parameters.
+var (
+	String   = &Type{Name: "string", validate: func(string) error { return nil }}
+	Int      = &Type{Name: "int", validate: validateInt}
+	Bool     = &Type{Name: "bool", validate: validateBool}
+	URL      = &Type{Name: "url", validate: validateURL}
+	Port     = &Type{Name: "port", validate: validatePort}
+	Duration = &Type{Name: "duration", validate: validateDuration}
+)
+
+// String returns the type as it is written in the schema, e.g. "enum(debug, info)".
+func (t *Type) String() string {
+	return t.Name
+}
+
+// Validate returns an error if the value does not match the type.
+func (t *Type) Validate(value string) error {
+	return t.validate(value)
+}
+
+// Enum returns the type of the value that is one of the values.
+func Enum(values ...string) *Type {
+	return &Type{
+		Name: "enum(" + strings.Join(values, ", ") + ")",
+		validate: func(value string) error {
+			for _, v := range values {
+				if v == value {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("must be one of: %s", strings.Join(values, ", "))
+		},
+	}
+}
+
+// Regex returns the type of the value that matches the regular expression as a whole.
+func Regex(pattern string) (*Type, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+
+	return &Type{
+		Name: "regex(" + pattern + ")",
+		validate: func(value string) error {
+			if !re.MatchString(value) {
+				return fmt.Errorf("must match %s", pattern)
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// ParseType parses the type as it is written in the @type annotation.
+func ParseType(s string) (*Type, error) {
+	for _, t := range []*Type{String, Int, Bool, URL, Port, Duration} {
+		if s == t.Name {
+			return t, nil
+		}
+	}
+
+	if arg, ok := typeArgument(s, "enum"); ok {
+		var values []string
+
+		for _, v := range strings.Split(arg, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) == 0 {
+			return nil, errors.New("enum must have values")
+		}
+
+		return Enum(values...), nil
+	}
+
+	if arg, ok := typeArgument(s, "regex"); ok {
+		return Regex(arg)
+	}
+
+	return nil, fmt.Errorf("unknown type %q", s)
+}
+
+// typeArgument returns the argument of the "name(argument)" type.
+func typeArgument(s, name string) (string, bool) {
+	if !strings.HasPrefix(s, name+"(") || !strings.HasSuffix(s, ")") {
+		return "", false
+	}
+
+	return s[len(name)+1 : len(s)-1], true
+}
+
+func validateInt(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return errors.New("must be an integer")
+	}
+
+	return nil
+}
+
+func validateBool(value string) error {
+	_, err := godenv.ParseBool(value)
+	return err
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+		return errors.New("must be an absolute URL")
+	}
+
+	return nil
+}
+
+func validatePort(value string) error {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		return errors.New("must be a port number from 1 to 65535")
+	}
+
+	return nil
+}
+
+func validateDuration(value string) error {
+	if _, err := time.ParseDuration(value); err != nil {
+		return errors.New("must be a duration, e.g. 1m30s")
+	}
+
+	return nil
+}
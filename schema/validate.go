@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/youla-dev/godenv"
+)
+
+// Violation is a variable that does not conform to the schema.
+type Violation struct {
+	Key       string          `json:"key"`
+	Message   string          `json:"message"`
+	Pos       godenv.Position `json:"position"`        // the assignment of the variable, zero if it is not set
+	SchemaPos godenv.Position `json:"schema_position"` // the declaration of the variable, zero if it is not declared
+}
+
+// String returns the violation in the "file:line:column: message (declared at file:line:column)" form.
+func (v Violation) String() string {
+	s := v.Pos.String() + ": " + v.Message
+	if v.SchemaPos.Line > 0 {
+		s += " (declared at " + v.SchemaPos.String() + ")"
+	}
+
+	return s
+}
+
+// ValidateOption configures the validation.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	allowUnknown bool
+}
+
+// AllowUnknown allows the variables that are not declared in the schema.
+func AllowUnknown() ValidateOption {
+	return func(o *validateOptions) {
+		o.allowUnknown = true
+	}
+}
+
+// Validate checks the variables against the schema and returns the violations: the required variables that are
+// not set, the values that do not match the declared types, and the variables that are not declared.
+// The violations of the declared variables go first, in the order of the declaration.
+func (s *Schema) Validate(vars *godenv.Vars, opts ...ValidateOption) []Violation {
+	o := &validateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var violations []Violation
+
+	for _, f := range s.Fields {
+		variable, ok := vars.Variable(f.Name)
+
+		switch {
+		case (!ok || variable.Value == "") && f.Required:
+			message := fmt.Sprintf("required variable %s is not set", f.Name)
+			if ok {
+				message = fmt.Sprintf("required variable %s is empty", f.Name)
+			}
+
+			violations = append(violations, Violation{Key: f.Name, Message: message, Pos: variable.Pos, SchemaPos: f.Pos})
+		case !ok || variable.Value == "":
+			// the optional variable is not set, the default is used
+		default:
+			if err := f.Type.Validate(variable.Value); err != nil {
+				violations = append(violations, Violation{
+					Key:       f.Name,
+					Message:   fmt.Sprintf("variable %s of type %s: %v", f.Name, f.Type, err),
+					Pos:       variable.Pos,
+					SchemaPos: f.Pos,
+				})
+			}
+		}
+	}
+
+	if o.allowUnknown {
+		return violations
+	}
+
+	for _, key := range vars.Keys() {
+		if s.Field(key) != nil {
+			continue
+		}
+
+		variable, _ := vars.Variable(key)
+		violations = append(violations, Violation{
+			Key:     key,
+			Message: fmt.Sprintf("variable %s is not declared in the schema", key),
+			Pos:     variable.Pos,
+		})
+	}
+
+	return violations
+}
+
+// Defaults returns the default values of the declared variables that are not set.
+func (s *Schema) Defaults(vars *godenv.Vars) map[string]string {
+	defaults := make(map[string]string)
+
+	for _, f := range s.Fields {
+		if value, ok := vars.Lookup(f.Name); f.HasDefault && (!ok || value == "") {
+			defaults[f.Name] = f.Default
+		}
+	}
+
+	return defaults
+}
+
+// DefaultVars returns the default values of the declared variables as the variables of godenv.OriginDefault
+// positioned at the declarations. The result is meant to be the lowest layer of godenv.Merge.
+func (s *Schema) DefaultVars() *godenv.Vars {
+	var variables []godenv.Variable
+
+	for _, f := range s.Fields {
+		if f.HasDefault {
+			variables = append(variables, godenv.Variable{Name: f.Name, Value: f.Default, Pos: f.Pos, Origin: godenv.OriginDefault})
+		}
+	}
+
+	return godenv.NewVarsOf(variables...)
+}
+
+// Missing returns the violations for the declared variables that are not set, whether they are required or not.
+func (s *Schema) Missing(vars *godenv.Vars) []Violation {
+	var violations []Violation
+
+	for _, f := range s.Fields {
+		if _, ok := vars.Lookup(f.Name); !ok {
+			violations = append(violations, Violation{
+				Key:       f.Name,
+				Message:   fmt.Sprintf("variable %s is not set", f.Name),
+				SchemaPos: f.Pos,
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,155 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+	"github.com/youla-dev/godenv/schema"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Parse(".env.example", []byte(example))
+	require.NoError(t, err)
+
+	schemaPos := func(line int) godenv.Position {
+		return godenv.Position{Filename: ".env.example", Line: line, Column: 1}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []schema.ValidateOption
+		expected []schema.Violation
+	}{
+		{
+			name:  "valid",
+			input: "HTTP_ADDR=https://example.com\nLOG_LEVEL=warn\nPORT=443\nRELEASE=app-12\nDEBUG=true\nWORKERS=8\nNAME=\n",
+		},
+		{
+			name:  "required only",
+			input: "HTTP_ADDR=https://example.com\n",
+		},
+		{
+			name:  "required variable is not set",
+			input: "PORT=443\n",
+			expected: []schema.Violation{
+				{Key: "HTTP_ADDR", Message: "required variable HTTP_ADDR is not set", SchemaPos: schemaPos(6)},
+			},
+		},
+		{
+			name:  "required variable is empty",
+			input: "PORT=443\nHTTP_ADDR=\n",
+			expected: []schema.Violation{
+				{
+					Key:       "HTTP_ADDR",
+					Message:   "required variable HTTP_ADDR is empty",
+					Pos:       godenv.Position{Line: 2, Column: 1},
+					SchemaPos: schemaPos(6),
+				},
+			},
+		},
+		{
+			name:  "invalid values",
+			input: "HTTP_ADDR=localhost\nLOG_LEVEL=trace\nPORT=100000\nTIMEOUT=30\nWORKERS=four\n",
+			expected: []schema.Violation{
+				{
+					Key:       "HTTP_ADDR",
+					Message:   "variable HTTP_ADDR of type url: must be an absolute URL",
+					Pos:       godenv.Position{Line: 1, Column: 1},
+					SchemaPos: schemaPos(6),
+				},
+				{
+					Key:       "LOG_LEVEL",
+					Message:   "variable LOG_LEVEL of type enum(debug, info, warn, error): must be one of: debug, info, warn, error",
+					Pos:       godenv.Position{Line: 2, Column: 1},
+					SchemaPos: schemaPos(10),
+				},
+				{
+					Key:       "PORT",
+					Message:   "variable PORT of type port: must be a port number from 1 to 65535",
+					Pos:       godenv.Position{Line: 3, Column: 1},
+					SchemaPos: schemaPos(13),
+				},
+				{
+					Key:       "TIMEOUT",
+					Message:   "variable TIMEOUT of type duration: must be a duration, e.g. 1m30s",
+					Pos:       godenv.Position{Line: 4, Column: 1},
+					SchemaPos: schemaPos(17),
+				},
+				{
+					Key:       "WORKERS",
+					Message:   "variable WORKERS of type int: must be an integer",
+					Pos:       godenv.Position{Line: 5, Column: 1},
+					SchemaPos: schemaPos(26),
+				},
+			},
+		},
+		{
+			name:  "unknown variable",
+			input: "HTTP_ADDR=https://example.com\nSECRET=1\n",
+			expected: []schema.Violation{
+				{
+					Key:     "SECRET",
+					Message: "variable SECRET is not declared in the schema",
+					Pos:     godenv.Position{Line: 2, Column: 1},
+				},
+			},
+		},
+		{
+			name:  "allowed unknown variable",
+			input: "HTTP_ADDR=https://example.com\nSECRET=1\n",
+			opts:  []schema.ValidateOption{schema.AllowUnknown()},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			vars := readVars(t, tc.input)
+			assert.Equal(t, tc.expected, s.Validate(vars, tc.opts...))
+		})
+	}
+}
+
+func TestSchema_Defaults(t *testing.T) {
+	t.Parallel()
+
+	s, err := schema.Parse(".env.example", []byte(example))
+	require.NoError(t, err)
+
+	vars := readVars(t, "LOG_LEVEL=debug\nTIMEOUT=\n")
+	assert.Equal(t, map[string]string{"TIMEOUT": "30s"}, s.Defaults(vars))
+}
+
+func TestViolation_String(t *testing.T) {
+	t.Parallel()
+
+	v := schema.Violation{
+		Key:       "PORT",
+		Message:   "variable PORT of type port: must be a port number from 1 to 65535",
+		Pos:       godenv.Position{Filename: ".env", Line: 3, Column: 1},
+		SchemaPos: godenv.Position{Filename: ".env.example", Line: 13, Column: 1},
+	}
+	assert.Equal(t, ".env:3:1: variable PORT of type port: must be a port number from 1 to 65535 (declared at .env.example:13:1)", v.String())
+
+	v.SchemaPos = godenv.Position{}
+	assert.Equal(t, ".env:3:1: variable PORT of type port: must be a port number from 1 to 65535", v.String())
+}
+
+func readVars(t *testing.T, input string) *godenv.Vars {
+	t.Helper()
+
+	vars, err := godenv.Read(strings.NewReader(input))
+	require.NoError(t, err)
+
+	return vars
+}
@@ -0,0 +1,144 @@
+package godenv
+
+import (
+	"os"
+)
+
+// Source is a set of variables, such as the variables of .env files (*Vars), of the environment of the process
+// (EnvSource), of a map (MapSource), or of several sources layered with precedence (Layered).
+type Source interface {
+	// Lookup returns the value of the variable and reports whether the variable is set.
+	Lookup(key string) (string, bool)
+	// Keys returns the names of the variables.
+	Keys() []string
+}
+
+var (
+	_ Source = (*Vars)(nil)
+	_ Source = EnvSource{}
+	_ Source = MapSource(nil)
+	_ Source = (*Layered)(nil)
+)
+
+// EnvSource is the environment of the process. The variables are looked up at the time of the call.
+type EnvSource struct{}
+
+// Lookup returns the value of the environment variable, see os.LookupEnv.
+func (EnvSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys returns the names of the environment variables in the order of os.Environ.
+func (EnvSource) Keys() []string {
+	return FromEnviron(os.Environ()).Keys()
+}
+
+// MapSource is a static set of variables, e.g. the built-in defaults or the values set on the command line.
+type MapSource map[string]string
+
+// Lookup returns the value of the variable and reports whether the variable is set.
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Keys returns the sorted names of the variables.
+func (m MapSource) Keys() []string {
+	return sortedKeys(m)
+}
+
+// Layered combines the sources: the later sources take precedence over the earlier ones, so the sources
+// are given from the lowest precedence to the highest, e.g. the defaults, the files, the environment, and
+// the values set on the command line:
+//
+//	src := godenv.NewLayered(defaults, files, godenv.EnvSource{}, godenv.MapSource(flags))
+type Layered struct {
+	sources []Source
+}
+
+// NewLayered returns the combination of the sources, from the lowest precedence to the highest. Nil sources are skipped.
+func NewLayered(sources ...Source) *Layered {
+	l := &Layered{}
+
+	for _, src := range sources {
+		if src != nil {
+			l.sources = append(l.sources, src)
+		}
+	}
+
+	return l
+}
+
+// Lookup returns the value of the variable from the source of the highest precedence that has the variable.
+func (l *Layered) Lookup(key string) (string, bool) {
+	for i := len(l.sources) - 1; i >= 0; i-- {
+		if value, ok := l.sources[i].Lookup(key); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Keys returns the names of the variables of all the sources in the order of their first appearance.
+func (l *Layered) Keys() []string {
+	var keys []string
+
+	seen := make(map[string]bool)
+
+	for _, src := range l.sources {
+		for _, key := range src.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// Vars returns the current variables of the sources along with the values they override, see Vars.Explain.
+// The variables of *Vars keep their positions, the variables of EnvSource come from OriginEnvironment,
+// and the variables of the other sources come from OriginOverride.
+func (l *Layered) Vars() *Vars {
+	layers := make([]*Vars, 0, len(l.sources))
+
+	for _, src := range l.sources {
+		layers = append(layers, sourceVars(src))
+	}
+
+	return Merge(layers...)
+}
+
+// FromSource returns the variables of the source, so the typed accessors, such as Int and Duration,
+// decode the values of any source. See Layered.Vars for the origins of the variables.
+func FromSource(src Source) *Vars {
+	if src == nil {
+		return NewVarsOf()
+	}
+
+	return sourceVars(src)
+}
+
+// sourceVars returns the variables of the source.
+func sourceVars(src Source) *Vars {
+	switch src := src.(type) {
+	case *Vars:
+		return src
+	case *Layered:
+		return src.Vars()
+	case EnvSource:
+		return FromEnviron(os.Environ())
+	}
+
+	var variables []Variable
+
+	for _, key := range src.Keys() {
+		if value, ok := src.Lookup(key); ok {
+			variables = append(variables, Variable{Name: key, Value: value, Origin: OriginOverride})
+		}
+	}
+
+	return NewVarsOf(variables...)
+}
@@ -0,0 +1,131 @@
+package godenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+// setenv sets the environment variable for the duration of the test, like t.Setenv in Go 1.17.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	old, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLayered(t *testing.T) {
+	setenv(t, "GODENV_TEST_ADDR", ":9090")
+
+	embedded, err := godenv.ReadFS(fstest.MapFS{
+		"defaults.env": {Data: []byte("GODENV_TEST_ADDR=:80\nGODENV_TEST_LEVEL=info\nGODENV_TEST_NAME=api\n")},
+	}, "defaults.env")
+	require.NoError(t, err)
+
+	src := godenv.NewLayered(
+		godenv.MapSource{"GODENV_TEST_LEVEL": "warn", "GODENV_TEST_TIMEOUT": "5s"},
+		embedded,
+		nil,
+		godenv.EnvSource{},
+		godenv.MapSource{"GODENV_TEST_LEVEL": "debug"},
+	)
+
+	for key, expected := range map[string]string{
+		"GODENV_TEST_ADDR":    ":9090",
+		"GODENV_TEST_LEVEL":   "debug",
+		"GODENV_TEST_NAME":    "api",
+		"GODENV_TEST_TIMEOUT": "5s",
+	} {
+		value, ok := src.Lookup(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, expected, value, key)
+	}
+
+	_, ok := src.Lookup("GODENV_TEST_MISSING")
+	assert.False(t, ok)
+
+	keys := src.Keys()
+	assert.Equal(t, []string{"GODENV_TEST_LEVEL", "GODENV_TEST_TIMEOUT", "GODENV_TEST_ADDR", "GODENV_TEST_NAME"}, keys[:4])
+	assert.Contains(t, keys, "PATH", "the keys of the environment are included")
+
+	e, ok := src.Vars().Explain("GODENV_TEST_LEVEL")
+	require.True(t, ok)
+	assert.Equal(t, godenv.Explanation{
+		Variable: godenv.Variable{Name: "GODENV_TEST_LEVEL", Value: "debug", Origin: godenv.OriginOverride},
+		Overridden: []godenv.Variable{
+			{Name: "GODENV_TEST_LEVEL", Value: "info", Pos: godenv.Position{Filename: "defaults.env", Line: 2, Column: 1}},
+			{Name: "GODENV_TEST_LEVEL", Value: "warn", Origin: godenv.OriginOverride},
+		},
+	}, e)
+
+	e, ok = src.Vars().Explain("GODENV_TEST_ADDR")
+	require.True(t, ok)
+	assert.Equal(t, godenv.OriginEnvironment, e.Origin)
+}
+
+func TestMapSource(t *testing.T) {
+	t.Parallel()
+
+	src := godenv.MapSource{"B": "2", "A": "1"}
+	assert.Equal(t, []string{"A", "B"}, src.Keys())
+
+	value, ok := src.Lookup("A")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	src := godenv.NewLayered(
+		godenv.MapSource{"HOST": "localhost", "PORT": "80"},
+		godenv.MapSource{"PORT": "8080"},
+	)
+
+	vars, err := godenv.Read(strings.NewReader("ADDR=${HOST}:${PORT}\nURL=http://${ADDR}/${PATH_PREFIX:-api}\n"),
+		godenv.WithSource(src))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:8080", vars.Get("ADDR"))
+	assert.Equal(t, "http://localhost:8080/api", vars.Get("URL"))
+
+	vars, err = godenv.Read(strings.NewReader("ADDR=${HOST}:${PORT}\n"), godenv.WithSource(nil))
+	require.NoError(t, err)
+	assert.Equal(t, ":", vars.Get("ADDR"), "a nil source limits the expansion to the variables of the files")
+}
+
+func TestFromSource(t *testing.T) {
+	t.Parallel()
+
+	vars := godenv.FromSource(godenv.NewLayered(
+		godenv.MapSource{"TIMEOUT": "5s", "WORKERS": "4"},
+		godenv.MapSource{"TIMEOUT": "10s"},
+	))
+
+	timeout, err := vars.Duration("TIMEOUT", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, timeout)
+
+	workers, err := vars.Int("WORKERS", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, workers)
+
+	_, err = godenv.FromSource(godenv.MapSource{"WORKERS": "four"}).Int("WORKERS", 1)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, godenv.FromSource(nil).Len())
+}
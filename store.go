@@ -0,0 +1,164 @@
+package godenv
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is an immutable set of variables, see Store. Snapshot is safe for concurrent use.
+//
+// The typed getters return the default value if the variable is not set, is empty, or cannot be parsed.
+type Snapshot struct {
+	vars    *Vars
+	version uint64
+}
+
+// Vars returns the variables of the snapshot. The variables must not be modified.
+func (s *Snapshot) Vars() *Vars {
+	return s.vars
+}
+
+// Version returns the number of the snapshot: 1 for the snapshot the store is created with,
+// incremented by every swap.
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// Lookup returns the value of the variable and reports whether the variable is set.
+func (s *Snapshot) Lookup(key string) (string, bool) {
+	return s.vars.Lookup(key)
+}
+
+// String returns the value of the variable, or the default value if the variable is not set or is empty.
+func (s *Snapshot) String(key, def string) string {
+	if value := s.vars.Get(key); value != "" {
+		return value
+	}
+
+	return def
+}
+
+// Int returns the value of the variable as int, see Vars.Int.
+func (s *Snapshot) Int(key string, def int) int {
+	n, err := s.vars.Int(key, def)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
+
+// Bool returns the value of the variable as bool, see Vars.Bool.
+func (s *Snapshot) Bool(key string, def bool) bool {
+	b, err := s.vars.BoolOr(key, def)
+	if err != nil {
+		return def
+	}
+
+	return b
+}
+
+// Duration returns the value of the variable as time.Duration, see Vars.Duration.
+func (s *Snapshot) Duration(key string, def time.Duration) time.Duration {
+	d, err := s.vars.Duration(key, def)
+	if err != nil {
+		return def
+	}
+
+	return d
+}
+
+// Store holds the current snapshot of the variables. The snapshot is read without locks,
+// so many goroutines can read it while a reloader, e.g. Watcher, swaps it. Store is safe for concurrent use.
+//
+//	w, err := godenv.NewWatcher(files)
+//	...
+//	store := godenv.NewStore(w.Vars())
+//	go func() {
+//		for event := range w.Events() {
+//			if event.Err == nil {
+//				store.Swap(event.Vars)
+//			}
+//		}
+//	}()
+//
+//	timeout := store.Load().Duration("TIMEOUT", 5*time.Second)
+type Store struct {
+	current atomic.Value // *Snapshot
+
+	mu          sync.Mutex // serializes the swaps and the changes of the subscribers
+	subscribers map[uint64]func(old, new *Snapshot)
+	order       []uint64 // the subscribers in the order of subscription
+	next        uint64
+}
+
+// NewStore returns the store of the variables. Nil vars means no variables.
+func NewStore(vars *Vars) *Store {
+	s := &Store{subscribers: make(map[uint64]func(old, new *Snapshot))}
+	s.current.Store(&Snapshot{vars: orEmpty(vars), version: 1})
+
+	return s
+}
+
+// Load returns the current snapshot. The snapshot stays intact when the store is swapped,
+// so the variables read from one snapshot are consistent.
+func (s *Store) Load() *Snapshot {
+	return s.current.Load().(*Snapshot)
+}
+
+// Swap replaces the snapshot with the variables and returns the new snapshot.
+// The subscribers are called before Swap returns, in the order of subscription;
+// the swaps are serialized, so the subscribers see the snapshots in order.
+func (s *Store) Swap(vars *Vars) *Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.Load()
+	snapshot := &Snapshot{vars: orEmpty(vars), version: old.version + 1}
+	s.current.Store(snapshot)
+
+	for _, id := range s.order {
+		s.subscribers[id](old, snapshot)
+	}
+
+	return snapshot
+}
+
+// Subscribe makes the store call the function on every swap, and returns the function that cancels the subscription.
+// The function must not call Swap, Subscribe or the cancel functions, the store is locked while it runs.
+func (s *Store) Subscribe(fn func(old, new *Snapshot)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	id := s.next
+	s.subscribers[id] = fn
+	s.order = append(s.order, id)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			delete(s.subscribers, id)
+
+			for i, other := range s.order {
+				if other == id {
+					s.order = append(s.order[:i], s.order[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+func orEmpty(vars *Vars) *Vars {
+	if vars == nil {
+		return NewVars(nil)
+	}
+
+	return vars
+}
@@ -0,0 +1,150 @@
+package godenv_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+func readVars(t *testing.T, input string) *godenv.Vars {
+	t.Helper()
+
+	vars, err := godenv.Read(strings.NewReader(input))
+	require.NoError(t, err)
+
+	return vars
+}
+
+func TestSnapshot_Getters(t *testing.T) {
+	t.Parallel()
+
+	store := godenv.NewStore(readVars(t, "NAME=api\nEMPTY=\nPORT=8080\nDEBUG=true\nTIMEOUT=5s\nINVALID=five\n"))
+	snapshot := store.Load()
+
+	assert.Equal(t, uint64(1), snapshot.Version())
+	assert.Equal(t, "api", snapshot.String("NAME", "default"))
+	assert.Equal(t, "default", snapshot.String("EMPTY", "default"))
+	assert.Equal(t, "default", snapshot.String("MISSING", "default"))
+	assert.Equal(t, 8080, snapshot.Int("PORT", 80))
+	assert.Equal(t, 80, snapshot.Int("MISSING", 80))
+	assert.Equal(t, 80, snapshot.Int("INVALID", 80))
+	assert.True(t, snapshot.Bool("DEBUG", false))
+	assert.True(t, snapshot.Bool("INVALID", true))
+	assert.Equal(t, 5*time.Second, snapshot.Duration("TIMEOUT", time.Second))
+	assert.Equal(t, time.Second, snapshot.Duration("INVALID", time.Second))
+
+	value, ok := snapshot.Lookup("EMPTY")
+	assert.True(t, ok)
+	assert.Empty(t, value)
+
+	assert.Zero(t, godenv.NewStore(nil).Load().Vars().Len())
+}
+
+func TestStore_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	store := godenv.NewStore(readVars(t, "PORT=1\n"))
+
+	var calls []string
+
+	first := store.Subscribe(func(old, new *godenv.Snapshot) {
+		calls = append(calls, "first "+old.String("PORT", "")+"->"+new.String("PORT", ""))
+	})
+	store.Subscribe(func(old, new *godenv.Snapshot) {
+		calls = append(calls, "second "+strconv.FormatUint(new.Version(), 10))
+	})
+
+	old := store.Load()
+	snapshot := store.Swap(readVars(t, "PORT=2\n"))
+
+	assert.Same(t, snapshot, store.Load())
+	assert.Equal(t, 1, old.Int("PORT", 0), "the old snapshot stays intact")
+
+	first()
+	first()
+	store.Swap(readVars(t, "PORT=3\n"))
+
+	assert.Equal(t, []string{"first 1->2", "second 2", "second 3"}, calls)
+}
+
+func TestStore_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	const (
+		readers = 8
+		swaps   = 500
+	)
+
+	store := godenv.NewStore(readVars(t, "A=0\nB=0\n"))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		versions []uint64
+		done     = make(chan struct{})
+	)
+
+	unsubscribe := store.Subscribe(func(old, new *godenv.Snapshot) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		versions = append(versions, new.Version())
+	})
+	defer unsubscribe()
+
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				snapshot := store.Load()
+				assert.Equal(t, snapshot.Int("A", -1), snapshot.Int("B", -2), "the snapshot is consistent")
+			}
+		}()
+	}
+
+	var swappers sync.WaitGroup
+
+	for i := 0; i < 2; i++ {
+		swappers.Add(1)
+
+		go func() {
+			defer swappers.Done()
+
+			for j := 1; j <= swaps; j++ {
+				n := strconv.Itoa(j)
+				store.Swap(readVars(t, "A="+n+"\nB="+n+"\n"))
+			}
+		}()
+	}
+
+	swappers.Wait()
+	close(done)
+	wg.Wait()
+
+	assert.Equal(t, uint64(2*swaps+1), store.Load().Version())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, versions, 2*swaps)
+
+	for i, version := range versions {
+		assert.Equal(t, uint64(i+2), version, "the subscribers see the snapshots in order")
+	}
+}
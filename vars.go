@@ -0,0 +1,150 @@
+package godenv
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// Variable is a variable assigned in an .env file.
+type Variable struct {
+	Name   string
+	Value  string
+	Pos    Position // the assignment that set the value, or the declaration of the default value
+	Origin Origin   // where the value comes from
+}
+
+// Vars is a set of variables read from .env files, along with the positions of their assignments.
+//
+// The values of the secrets are redacted when Vars is printed with fmt or logged with log/slog (Go 1.21 and later),
+// see IsRedacted.
+type Vars struct {
+	vars    map[string]Variable
+	keys    []string              // in the order of the first assignment
+	redact  []string              // the patterns of the names of the secrets
+	history map[string][]Variable // the overridden variables, the earliest first
+}
+
+// NewVars returns Vars that contains the variables of the map. The variables have no positions.
+// The values are redacted according to DefaultRedactPatterns.
+func NewVars(values map[string]string) *Vars {
+	v := newVars(DefaultRedactPatterns())
+
+	for _, key := range sortedKeys(values) {
+		v.set(Variable{Name: key, Value: values[key]})
+	}
+
+	return v
+}
+
+func newVars(redact []string) *Vars {
+	return &Vars{
+		vars:    make(map[string]Variable),
+		redact:  redact,
+		history: make(map[string][]Variable),
+	}
+}
+
+// Read reads an env file from io.Reader, returning the variables with their positions.
+// See Parse for the details.
+func Read(r io.Reader, opts ...Option) (*Vars, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLoader(newOptions(opts))
+
+	if err := l.load(l.o.filename, input); err != nil {
+		return nil, err
+	}
+
+	if err := l.resolveReferences(); err != nil {
+		return nil, err
+	}
+
+	return l.vars, nil
+}
+
+// ReadFile reads an env file, returning the variables with their positions.
+// See ParseFile for the details.
+func ReadFile(filename string, opts ...Option) (*Vars, error) {
+	l := newLoader(newOptions(opts))
+
+	if err := l.loadFile(filename); err != nil {
+		return nil, err
+	}
+
+	if err := l.resolveReferences(); err != nil {
+		return nil, err
+	}
+
+	return l.vars, nil
+}
+
+// ReadFS reads an env file from fsys, returning the variables with their positions.
+// See ParseFS for the details.
+func ReadFS(fsys fs.FS, name string, opts ...Option) (*Vars, error) {
+	return ReadFile(name, append(opts, WithFS(fsys))...)
+}
+
+// Lookup returns the value of the variable and reports whether the variable is set.
+func (v *Vars) Lookup(key string) (string, bool) {
+	variable, ok := v.vars[key]
+	return variable.Value, ok
+}
+
+// Get returns the value of the variable, or an empty string if the variable is not set.
+func (v *Vars) Get(key string) string {
+	return v.vars[key].Value
+}
+
+// Variable returns the variable along with the position of its assignment.
+func (v *Vars) Variable(key string) (Variable, bool) {
+	variable, ok := v.vars[key]
+	return variable, ok
+}
+
+// Keys returns the names of the variables in the order of their first assignment.
+func (v *Vars) Keys() []string {
+	return append([]string(nil), v.keys...)
+}
+
+// Len returns the number of the variables.
+func (v *Vars) Len() int {
+	return len(v.keys)
+}
+
+// Map returns the variables as a map of keys and values.
+func (v *Vars) Map() map[string]string {
+	values := make(map[string]string, len(v.vars))
+
+	for key, variable := range v.vars {
+		values[key] = variable.Value
+	}
+
+	return values
+}
+
+// set assigns the variable. The variable keeps its place in the order of the keys, if it is already set,
+// and the overridden variable is kept in the history, see Explain.
+func (v *Vars) set(variable Variable) {
+	if old, ok := v.vars[variable.Name]; ok {
+		v.history[variable.Name] = append(v.history[variable.Name], old)
+	} else {
+		v.keys = append(v.keys, variable.Name)
+	}
+
+	v.vars[variable.Name] = variable
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,62 @@
+package godenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, ".env")
+	common := filepath.Join(dir, "common.env")
+
+	require.NoError(t, os.WriteFile(main, []byte("FOO=main\n#include common.env\nBAZ=main\n"), 0o600))
+	require.NoError(t, os.WriteFile(common, []byte("# common\nBAR=common\nFOO=common\n"), 0o600))
+
+	vars, err := godenv.ReadFile(main)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"FOO", "BAR", "BAZ"}, vars.Keys())
+	assert.Equal(t, 3, vars.Len())
+	assert.Equal(t, map[string]string{"FOO": "common", "BAR": "common", "BAZ": "main"}, vars.Map())
+
+	variable, ok := vars.Variable("FOO")
+	require.True(t, ok)
+	assert.Equal(t, godenv.Variable{
+		Name:  "FOO",
+		Value: "common",
+		Pos:   godenv.Position{Filename: common, Line: 3, Column: 1},
+	}, variable)
+
+	value, ok := vars.Lookup("BAZ")
+	assert.True(t, ok)
+	assert.Equal(t, "main", value)
+
+	_, ok = vars.Lookup("QUX")
+	assert.False(t, ok)
+	assert.Empty(t, vars.Get("QUX"))
+}
+
+func TestRead(t *testing.T) {
+	vars, err := godenv.Read(strings.NewReader("FOO=bar\n\nBAZ<<EOF\nqux\nEOF\n"))
+	require.NoError(t, err)
+
+	variable, ok := vars.Variable("BAZ")
+	require.True(t, ok)
+	assert.Equal(t, godenv.Position{Line: 3, Column: 1}, variable.Pos)
+	assert.Equal(t, "qux", variable.Value)
+}
+
+func TestNewVars(t *testing.T) {
+	vars := godenv.NewVars(map[string]string{"B": "2", "A": "1"})
+
+	assert.Equal(t, []string{"A", "B"}, vars.Keys())
+	assert.Equal(t, "2", vars.Get("B"))
+}
@@ -0,0 +1,298 @@
+package godenv
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = 500 * time.Millisecond
+	defaultWatchDebounce = 100 * time.Millisecond
+)
+
+// Event describes a reload of the watched files.
+type Event struct {
+	Vars    *Vars    // the variables after the reload, the last good ones if the files cannot be parsed
+	Changes []Change // the changes of the variables, sorted by the key; empty on errors
+	Err     error    // the error of parsing, if any
+}
+
+// WatchOption configures Watcher.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	parse    []Option
+	interval time.Duration
+	debounce time.Duration
+	onChange func(Event)
+}
+
+// WatchParseOptions sets the options the files are parsed with.
+func WatchParseOptions(opts ...Option) WatchOption {
+	return func(o *watchOptions) {
+		o.parse = append(o.parse, opts...)
+	}
+}
+
+// WatchInterval sets how often the files are checked for changes. The default interval is 500ms.
+func WatchInterval(interval time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = interval
+	}
+}
+
+// WatchDebounce sets how long the files must stay intact after a change before they are reloaded,
+// so a burst of writes causes a single reload. The default is 100ms.
+func WatchDebounce(debounce time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = debounce
+	}
+}
+
+// OnChange makes Watcher call the function on every reload instead of sending the events to the channel.
+// The function is called from the goroutine of Watcher, one call at a time. The function may call Watcher.Close.
+func OnChange(fn func(Event)) WatchOption {
+	return func(o *watchOptions) {
+		o.onChange = fn
+	}
+}
+
+// Watcher reloads the .env files when they change, including the files they include.
+//
+// The files are polled: their content is checked every WatchInterval. A reload that changes the variables,
+// or fails, is delivered as Event. If the files cannot be parsed, the last good variables are kept.
+// Watcher is safe for concurrent use.
+type Watcher struct {
+	filenames []string
+	o         watchOptions
+	events    chan Event
+
+	mu         sync.Mutex
+	vars       *Vars
+	delivering bool // the OnChange function is running
+
+	files     map[string]uint64 // the digests of the watched files, used by the goroutine of Watcher only
+	closeOnce sync.Once
+	closing   chan struct{}
+	done      chan struct{}
+}
+
+// NewWatcher loads the files and starts watching them. The variables of the later files override the earlier ones.
+// If the files cannot be loaded, NewWatcher returns the error.
+func NewWatcher(filenames []string, opts ...WatchOption) (*Watcher, error) {
+	o := watchOptions{
+		interval: defaultWatchInterval,
+		debounce: defaultWatchDebounce,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := &Watcher{
+		filenames: append([]string(nil), filenames...),
+		o:         o,
+		events:    make(chan Event),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	vars, loaded, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	w.vars = vars
+	w.files = loaded
+
+	go w.run()
+
+	return w, nil
+}
+
+// Vars returns the variables of the last successful load.
+func (w *Watcher) Vars() *Vars {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.vars
+}
+
+// Events returns the channel of the reloads. The channel is closed by Close.
+//
+// Unless OnChange is given, the events must be received: the files are not checked until the event is received.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Close stops watching the files and waits for the delivery of the pending event to finish.
+// Close can be called several times and from several goroutines, it always returns nil.
+//
+// If Close is called while the OnChange function runs, e.g. from the function itself,
+// it returns without waiting for the function to return.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+	})
+
+	w.mu.Lock()
+	delivering := w.delivering
+	w.mu.Unlock()
+
+	if !delivering {
+		<-w.done
+	}
+
+	return nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	defer close(w.events)
+
+	ticker := time.NewTicker(w.o.interval)
+	defer ticker.Stop()
+
+	debounce := time.NewTimer(w.o.debounce)
+	defer debounce.Stop()
+
+	if !debounce.Stop() {
+		<-debounce.C
+	}
+
+	for {
+		select {
+		case <-w.closing:
+			return
+		case <-ticker.C:
+			if files := w.digests(keys(w.files)); !equalDigests(files, w.files) {
+				w.files = files
+
+				if !debounce.Stop() {
+					select {
+					case <-debounce.C:
+					default:
+					}
+				}
+
+				debounce.Reset(w.o.debounce)
+			}
+		case <-debounce.C:
+			if event, ok := w.reload(); ok {
+				w.deliver(event)
+			}
+		}
+	}
+}
+
+// reload loads the files and reports whether the event should be delivered.
+func (w *Watcher) reload() (Event, bool) {
+	vars, loaded, err := w.load()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil {
+		// The included files are not known if the file cannot be parsed, so the files loaded before are kept watched.
+		for name, digest := range loaded {
+			w.files[name] = digest
+		}
+
+		return Event{Vars: w.vars, Err: err}, true
+	}
+
+	w.files = loaded
+
+	changes := Diff(w.vars, vars)
+	w.vars = vars
+
+	return Event{Vars: vars, Changes: changes}, len(changes) > 0
+}
+
+func (w *Watcher) deliver(event Event) {
+	if w.o.onChange != nil {
+		w.setDelivering(true)
+		defer w.setDelivering(false)
+
+		w.o.onChange(event)
+
+		return
+	}
+
+	select {
+	case w.events <- event:
+	case <-w.closing:
+	}
+}
+
+func (w *Watcher) setDelivering(delivering bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.delivering = delivering
+}
+
+// load reads the files and returns the variables along with the digests of the files that have been read.
+func (w *Watcher) load() (*Vars, map[string]uint64, error) {
+	l := newLoader(newOptions(w.o.parse))
+
+	for _, name := range w.filenames {
+		if err := l.loadFile(name); err != nil {
+			return nil, l.loaded, err
+		}
+	}
+
+	if err := l.resolveReferences(); err != nil {
+		return nil, l.loaded, err
+	}
+
+	return l.vars, l.loaded, nil
+}
+
+// digests returns the digests of the content of the files.
+func (w *Watcher) digests(names []string) map[string]uint64 {
+	fsys := newOptions(w.o.parse).fsys
+	files := make(map[string]uint64, len(names))
+
+	for _, name := range names {
+		files[name] = digest(readFile(fsys, name))
+	}
+
+	return files
+}
+
+// digest returns the digest of the content of the file, or 0 if the file cannot be read.
+func digest(content []byte, err error) uint64 {
+	if err != nil {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(content)
+
+	return h.Sum64() | 1 // never 0, so an empty file differs from a missing one
+}
+
+func equalDigests(a, b map[string]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, digest := range a {
+		if other, ok := b[name]; !ok || other != digest {
+			return false
+		}
+	}
+
+	return true
+}
+
+func keys(files map[string]uint64) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -0,0 +1,211 @@
+package godenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/youla-dev/godenv"
+)
+
+const (
+	watchInterval = 5 * time.Millisecond
+	watchTimeout  = 5 * time.Second
+)
+
+func writeEnv(t *testing.T, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+}
+
+func nextEvent(t *testing.T, w *godenv.Watcher) godenv.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-w.Events():
+		require.True(t, ok, "the channel is closed")
+		return event
+	case <-time.After(watchTimeout):
+		require.FailNow(t, "no event")
+		return godenv.Event{}
+	}
+}
+
+func changedKeys(changes []godenv.Change) map[string]godenv.ChangeKind {
+	keys := make(map[string]godenv.ChangeKind)
+	for _, change := range changes {
+		keys[change.Key] = change.Kind
+	}
+
+	return keys
+}
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	included := filepath.Join(dir, "included.env")
+
+	writeEnv(t, base, "A=1\nB=2\n")
+	writeEnv(t, local, "B=local\n#include included.env\n")
+	writeEnv(t, included, "C=3\n")
+
+	w, err := godenv.NewWatcher([]string{base, local}, godenv.WatchInterval(watchInterval), godenv.WatchDebounce(watchInterval))
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	assert.Equal(t, map[string]string{"A": "1", "B": "local", "C": "3"}, w.Vars().Map())
+
+	writeEnv(t, base, "A=one\nD=4\n")
+
+	event := nextEvent(t, w)
+	require.NoError(t, event.Err)
+	assert.Equal(t, map[string]godenv.ChangeKind{"A": godenv.Changed, "D": godenv.Added}, changedKeys(event.Changes))
+	assert.Equal(t, map[string]string{"A": "one", "B": "local", "C": "3", "D": "4"}, w.Vars().Map())
+
+	writeEnv(t, included, "")
+
+	event = nextEvent(t, w)
+	require.NoError(t, event.Err)
+	assert.Equal(t, map[string]godenv.ChangeKind{"C": godenv.Removed}, changedKeys(event.Changes), "the included files are watched")
+
+	writeEnv(t, base, "A='unterminated\n")
+
+	event = nextEvent(t, w)
+	assert.Error(t, event.Err)
+	assert.Empty(t, event.Changes)
+	assert.Equal(t, "one", event.Vars.Get("A"), "the last good variables are kept")
+	assert.Equal(t, "one", w.Vars().Get("A"))
+
+	writeEnv(t, base, "A=fixed\nD=4\n")
+
+	event = nextEvent(t, w)
+	require.NoError(t, event.Err)
+	assert.Equal(t, map[string]godenv.ChangeKind{"A": godenv.Changed}, changedKeys(event.Changes))
+
+	require.NoError(t, w.Close())
+
+	_, ok := <-w.Events()
+	assert.False(t, ok, "the channel is closed")
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), ".env")
+	writeEnv(t, name, "A=0\n")
+
+	var (
+		mu     sync.Mutex
+		events []godenv.Event
+	)
+
+	w, err := godenv.NewWatcher([]string{name},
+		godenv.WatchInterval(watchInterval),
+		godenv.WatchDebounce(300*time.Millisecond),
+		godenv.OnChange(func(event godenv.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			events = append(events, event)
+		}),
+	)
+	require.NoError(t, err)
+
+	for _, value := range []string{"1", "2", "3"} {
+		writeEnv(t, name, "A="+value+"\n")
+		time.Sleep(2 * watchInterval)
+	}
+
+	assert.Eventually(t, func() bool {
+		return w.Vars().Get("A") == "3"
+	}, watchTimeout, watchInterval)
+
+	require.NoError(t, w.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, events, 1, "a burst of writes causes a single reload")
+	assert.Equal(t, []godenv.Change{{
+		Kind: godenv.Changed,
+		Key:  "A",
+		Old:  godenv.Variable{Name: "A", Value: "0", Pos: godenv.Position{Filename: name, Line: 1, Column: 1}},
+		New:  godenv.Variable{Name: "A", Value: "3", Pos: godenv.Position{Filename: name, Line: 1, Column: 1}},
+	}}, events[0].Changes)
+}
+
+func TestWatcher_Close(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), ".env")
+	writeEnv(t, name, "A=0\n")
+
+	w, err := godenv.NewWatcher([]string{name}, godenv.WatchInterval(watchInterval), godenv.WatchDebounce(watchInterval))
+	require.NoError(t, err)
+
+	writeEnv(t, name, "A=1\n") // the event is never received
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, w.Close())
+			_ = w.Vars()
+		}()
+	}
+
+	wg.Wait()
+	assert.NoError(t, w.Close())
+}
+
+func TestWatcher_CloseOnChange(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), ".env")
+	writeEnv(t, name, "A=0\n")
+
+	watcher := make(chan *godenv.Watcher, 1)
+	closed := make(chan struct{})
+
+	w, err := godenv.NewWatcher([]string{name},
+		godenv.WatchInterval(watchInterval),
+		godenv.WatchDebounce(watchInterval),
+		godenv.OnChange(func(godenv.Event) {
+			assert.NoError(t, (<-watcher).Close(), "Close does not wait for the function that calls it")
+			close(closed)
+		}),
+	)
+	require.NoError(t, err)
+
+	watcher <- w
+
+	writeEnv(t, name, "A=1\n")
+
+	select {
+	case <-closed:
+	case <-time.After(watchTimeout):
+		require.FailNow(t, "Close called from OnChange has not returned")
+	}
+
+	assert.NoError(t, w.Close())
+}
+
+func TestNewWatcher_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := godenv.NewWatcher([]string{filepath.Join(t.TempDir(), "missing.env")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,51 @@
+package godenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/youla-dev/godenv/internal/ast"
+	"github.com/youla-dev/godenv/internal/printer"
+	"github.com/youla-dev/godenv/internal/scanner"
+)
+
+// Write writes the variables to w in the .env format, sorted by name.
+//
+// Every value is written in the most readable notation that keeps it intact when parsed back.
+// Values that contain both line breaks and quotes are written as heredocs. Write returns an error
+// if a value cannot be written at all, e.g. if it contains both quotes and a carriage return.
+func Write(w io.Writer, vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !scanner.IsIdentifier(name) {
+			return fmt.Errorf("invalid variable name: %q", name)
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	file := &ast.FileStatement{
+		Statements: make([]ast.Statement, 0, len(names)),
+	}
+
+	for _, name := range names {
+		file.Statements = append(file.Statements, printer.Assign(name, vars[name]))
+	}
+
+	return printer.Fprint(w, file)
+}
+
+// Marshal returns the variables in the .env format. See Write for details.
+func Marshal(vars map[string]string) (string, error) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, vars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}